@@ -0,0 +1,446 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"task_manager/Domain"
+	"time"
+)
+
+type Config struct {
+	Storage                 string
+	MongoURI                string
+	DatabaseName            string
+	TaskCollection          string
+	UserCollection          string
+	RefreshTokenCollection  string
+	RoleCollection          string
+	AuditCollection         string
+	CommentCollection       string
+	ProjectCollection       string
+	LabelCollection         string
+	TimeLogCollection       string
+	ReminderCollection      string
+	LoginThrottleCollection string
+	Port                    int
+	RequestTimeout          time.Duration
+	DBTimeout               time.Duration
+	ShutdownTimeout         time.Duration
+	DrainDelay              time.Duration
+	TrustedProxies          []string
+	JWTSecret               string
+	JWTKeysFile             string
+	JWTSigningKID           string
+	TokenTTL                time.Duration
+	RefreshTokenTTL         time.Duration
+	Workflow                domain.Workflow
+	RequireIfMatch          bool
+	TrashRetention          time.Duration
+	TrashPurgeInterval      time.Duration
+	RecurrenceInterval      time.Duration
+	ReminderInterval        time.Duration
+	ReminderPolicy          domain.ReminderPolicy
+	ReminderNotifier        string
+	ReminderWebhookURL      string
+	SMTPAddr                string
+	SMTPFrom                string
+	SMTPTo                  string
+	SMTPUsername            string
+	SMTPPassword            string
+	PasswordPolicy          domain.PasswordPolicy
+	PasswordDenylistFile    string
+	LockoutPolicy           domain.LockoutPolicy
+	RateLimits              map[string]domain.RateLimit
+}
+
+func (c *Config) Addr() string {
+	return ":" + strconv.Itoa(c.Port)
+}
+
+type setting struct {
+	env   string
+	flag  string
+	def   string
+	usage string
+}
+
+var settings = []setting{
+	{env: "STORAGE", flag: "storage", def: "mongo", usage: "storage backend: mongo or memory"},
+	{env: "MONGODB_URI", flag: "mongodb-uri", def: "mongodb://localhost:27017", usage: "MongoDB connection string"},
+	{env: "DATABASE_NAME", flag: "database", def: "taskdb", usage: "MongoDB database name"},
+	{env: "COLLECTION_NAME", flag: "task-collection", def: "tasks", usage: "MongoDB collection for tasks"},
+	{env: "USER_COLLECTION_NAME", flag: "user-collection", def: "users", usage: "MongoDB collection for users"},
+	{env: "REFRESH_TOKEN_COLLECTION_NAME", flag: "refresh-token-collection", def: "refresh_tokens", usage: "MongoDB collection for refresh tokens"},
+	{env: "ROLE_COLLECTION_NAME", flag: "role-collection", def: "roles", usage: "MongoDB collection for custom roles"},
+	{env: "AUDIT_COLLECTION_NAME", flag: "audit-collection", def: "audit_log", usage: "MongoDB collection for the audit log"},
+	{env: "COMMENT_COLLECTION_NAME", flag: "comment-collection", def: "comments", usage: "MongoDB collection for task comments"},
+	{env: "PROJECT_COLLECTION_NAME", flag: "project-collection", def: "projects", usage: "MongoDB collection for projects"},
+	{env: "LABEL_COLLECTION_NAME", flag: "label-collection", def: "labels", usage: "MongoDB collection for label definitions"},
+	{env: "TIMELOG_COLLECTION_NAME", flag: "timelog-collection", def: "timelogs", usage: "MongoDB collection for time logs and running timers"},
+	{env: "REMINDER_COLLECTION_NAME", flag: "reminder-collection", def: "reminders", usage: "MongoDB collection recording sent due-date reminders"},
+	{env: "LOGIN_THROTTLE_COLLECTION_NAME", flag: "login-throttle-collection", def: "login_throttle", usage: "MongoDB collection for failed-login counters of client IPs and unknown usernames"},
+	{env: "PORT", flag: "port", def: "8080", usage: "HTTP listen port"},
+	{env: "REQUEST_TIMEOUT", flag: "request-timeout", def: "30s", usage: "deadline for handling a whole HTTP request"},
+	{env: "DB_TIMEOUT", flag: "db-timeout", def: "10s", usage: "deadline for a single database operation"},
+	{env: "SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", def: "15s", usage: "how long to wait for in-flight requests when shutting down"},
+	{env: "DRAIN_DELAY", flag: "drain-delay", def: "5s", usage: "how long /readyz reports draining before the server stops accepting connections"},
+	{env: "TRUSTED_PROXIES", flag: "trusted-proxies", usage: "comma-separated IPs or CIDRs of reverse proxies allowed to set X-Forwarded-For (default none)"},
+	{env: "JWT_SECRET", flag: "jwt-secret", usage: "HS256 secret used to sign access tokens"},
+	{env: "JWT_KEYS_FILE", flag: "jwt-keys-file", usage: "JSON keyset with HS256 secrets and RS256/ES256 PEM keys"},
+	{env: "JWT_SIGNING_KID", flag: "jwt-signing-kid", usage: "kid of the key used to sign new tokens"},
+	{env: "JWT_TOKEN_TTL", flag: "token-ttl", def: "15m", usage: "lifetime of issued access tokens"},
+	{env: "TASK_WORKFLOW", flag: "task-workflow", usage: `allowed status transitions, e.g. "todo:in_progress;in_progress:done" (default built-in workflow)`},
+	{env: "REFRESH_TOKEN_TTL", flag: "refresh-token-ttl", def: "720h", usage: "lifetime of issued refresh tokens"},
+	{env: "REQUIRE_IF_MATCH", flag: "require-if-match", def: "false", usage: "reject task writes without an If-Match header"},
+	{env: "TRASH_RETENTION", flag: "trash-retention", def: "720h", usage: "how long deleted tasks stay restorable before they are purged"},
+	{env: "TRASH_PURGE_INTERVAL", flag: "trash-purge-interval", def: "1h", usage: "how often the trash is checked for tasks to purge"},
+	{env: "RECURRENCE_INTERVAL", flag: "recurrence-interval", def: "1m", usage: "how often overdue recurring tasks are checked for a next occurrence"},
+	{env: "REMINDER_INTERVAL", flag: "reminder-interval", def: "1m", usage: "how often tasks are checked for due-date reminders"},
+	{env: "REMINDER_WINDOWS", flag: "reminder-windows", def: "24h,1h", usage: `comma-separated durations before the due date at which to remind, e.g. "24h,1h"`},
+	{env: "REMINDER_OVERDUE_LOOKBACK", flag: "reminder-overdue-lookback", def: "168h", usage: "how long after the due date overdue tasks are still reported"},
+	{env: "REMINDER_NOTIFIER", flag: "reminder-notifier", def: "log", usage: "where reminders are sent: log, webhook or smtp"},
+	{env: "REMINDER_WEBHOOK_URL", flag: "reminder-webhook-url", usage: "URL the webhook notifier POSTs reminders to"},
+	{env: "SMTP_ADDR", flag: "smtp-addr", usage: "host:port of the SMTP server used by the smtp notifier"},
+	{env: "SMTP_FROM", flag: "smtp-from", usage: "sender address of reminder mails"},
+	{env: "SMTP_TO", flag: "smtp-to", usage: `recipient of reminder mails; "{username}" is replaced with the assignee's username`},
+	{env: "SMTP_USERNAME", flag: "smtp-username", usage: "SMTP username (no authentication when empty)"},
+	{env: "SMTP_PASSWORD", flag: "smtp-password", usage: "SMTP password"},
+	{env: "PASSWORD_MIN_LENGTH", flag: "password-min-length", def: "8", usage: "minimum password length"},
+	{env: "PASSWORD_REQUIRE", flag: "password-require", def: "upper,lower,digit", usage: "character classes every password needs: upper, lower, digit, symbol"},
+	{env: "PASSWORD_DENYLIST_FILE", flag: "password-denylist-file", usage: "file of breached passwords to reject, one per line"},
+	{env: "LOGIN_MAX_FAILURES", flag: "login-max-failures", def: "5", usage: "failed logins before an account is locked (0 disables)"},
+	{env: "LOGIN_IP_MAX_FAILURES", flag: "login-ip-max-failures", def: "50", usage: "failed logins before a client IP is locked (0 disables)"},
+	{env: "LOGIN_LOCKOUT", flag: "login-lockout", def: "1m", usage: "first lockout period, doubled on every further failure"},
+	{env: "LOGIN_LOCKOUT_MAX", flag: "login-lockout-max", def: "1h", usage: "longest lockout period"},
+	{env: "LOGIN_FAILURE_WINDOW", flag: "login-failure-window", def: "24h", usage: "how long failed logins are remembered"},
+	{env: "RATE_LIMIT_PUBLIC", flag: "rate-limit-public", def: "20/1m", usage: `requests per client IP to /register, /login, /token/refresh and /logout, e.g. "20/1m", or "off"`},
+	{env: "RATE_LIMIT_API", flag: "rate-limit-api", def: "300/1m", usage: `requests per user to authenticated routes, e.g. "300/1m", or "off"`},
+}
+
+// Load resolves the configuration from, in increasing order of precedence,
+// built-in defaults, the .env file, process environment and command-line flags.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("task_manager", flag.ContinueOnError)
+	envFile := fs.String("env-file", "", "path to a .env file (default .env if present)")
+	flagValues := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		flagValues[s.env] = fs.String(s.flag, "", fmt.Sprintf("%s (env %s)", s.usage, s.env))
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(settings))
+	for _, s := range settings {
+		values[s.env] = s.def
+	}
+
+	path := *envFile
+	if path == "" {
+		path = os.Getenv("ENV_FILE")
+	}
+	fileValues, err := readEnvFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	for _, s := range settings {
+		if v, ok := fileValues[s.env]; ok {
+			values[s.env] = v
+		}
+		if v, ok := os.LookupEnv(s.env); ok {
+			values[s.env] = v
+		}
+		if setFlags[s.flag] {
+			values[s.env] = *flagValues[s.env]
+		}
+	}
+
+	return parse(values)
+}
+
+func parse(values map[string]string) (*Config, error) {
+	var errs []error
+
+	cfg := &Config{
+		Storage:                 values["STORAGE"],
+		MongoURI:                values["MONGODB_URI"],
+		DatabaseName:            values["DATABASE_NAME"],
+		TaskCollection:          values["COLLECTION_NAME"],
+		UserCollection:          values["USER_COLLECTION_NAME"],
+		RefreshTokenCollection:  values["REFRESH_TOKEN_COLLECTION_NAME"],
+		RoleCollection:          values["ROLE_COLLECTION_NAME"],
+		AuditCollection:         values["AUDIT_COLLECTION_NAME"],
+		CommentCollection:       values["COMMENT_COLLECTION_NAME"],
+		ProjectCollection:       values["PROJECT_COLLECTION_NAME"],
+		LabelCollection:         values["LABEL_COLLECTION_NAME"],
+		TimeLogCollection:       values["TIMELOG_COLLECTION_NAME"],
+		ReminderCollection:      values["REMINDER_COLLECTION_NAME"],
+		LoginThrottleCollection: values["LOGIN_THROTTLE_COLLECTION_NAME"],
+		ReminderNotifier:        values["REMINDER_NOTIFIER"],
+		ReminderWebhookURL:      values["REMINDER_WEBHOOK_URL"],
+		SMTPAddr:                values["SMTP_ADDR"],
+		SMTPFrom:                values["SMTP_FROM"],
+		SMTPTo:                  values["SMTP_TO"],
+		SMTPUsername:            values["SMTP_USERNAME"],
+		SMTPPassword:            values["SMTP_PASSWORD"],
+		JWTSecret:               values["JWT_SECRET"],
+		JWTKeysFile:             values["JWT_KEYS_FILE"],
+		JWTSigningKID:           values["JWT_SIGNING_KID"],
+		PasswordDenylistFile:    values["PASSWORD_DENYLIST_FILE"],
+	}
+
+	switch cfg.Storage {
+	case "mongo":
+		if cfg.MongoURI == "" {
+			errs = append(errs, errors.New("MONGODB_URI is required for mongo storage"))
+		}
+		if cfg.DatabaseName == "" || cfg.TaskCollection == "" || cfg.UserCollection == "" || cfg.RefreshTokenCollection == "" || cfg.RoleCollection == "" || cfg.AuditCollection == "" || cfg.CommentCollection == "" || cfg.ProjectCollection == "" || cfg.LabelCollection == "" || cfg.TimeLogCollection == "" || cfg.ReminderCollection == "" || cfg.LoginThrottleCollection == "" {
+			errs = append(errs, errors.New("DATABASE_NAME and collection names must not be empty"))
+		}
+	case "memory":
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE must be mongo or memory, got %q", cfg.Storage))
+	}
+
+	port, err := strconv.Atoi(values["PORT"])
+	if err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be a number between 1 and 65535, got %q", values["PORT"]))
+	}
+	cfg.Port = port
+
+	requestTimeout, err := time.ParseDuration(values["REQUEST_TIMEOUT"])
+	if err != nil || requestTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("REQUEST_TIMEOUT must be a positive duration, got %q", values["REQUEST_TIMEOUT"]))
+	}
+	cfg.RequestTimeout = requestTimeout
+
+	dbTimeout, err := time.ParseDuration(values["DB_TIMEOUT"])
+	if err != nil || dbTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("DB_TIMEOUT must be a positive duration, got %q", values["DB_TIMEOUT"]))
+	}
+	cfg.DBTimeout = dbTimeout
+
+	shutdownTimeout, err := time.ParseDuration(values["SHUTDOWN_TIMEOUT"])
+	if err != nil || shutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT must be a positive duration, got %q", values["SHUTDOWN_TIMEOUT"]))
+	}
+	cfg.ShutdownTimeout = shutdownTimeout
+
+	drainDelay, err := time.ParseDuration(values["DRAIN_DELAY"])
+	if err != nil || drainDelay < 0 {
+		errs = append(errs, fmt.Errorf("DRAIN_DELAY must be a non-negative duration, got %q", values["DRAIN_DELAY"]))
+	}
+	cfg.DrainDelay = drainDelay
+
+	if cfg.JWTSecret == "" && cfg.JWTKeysFile == "" {
+		errs = append(errs, errors.New("JWT_SECRET or JWT_KEYS_FILE is required"))
+	}
+	if cfg.JWTSecret != "" && len(cfg.JWTSecret) < 32 {
+		errs = append(errs, errors.New("JWT_SECRET must be at least 32 characters"))
+	}
+
+	ttl, err := time.ParseDuration(values["JWT_TOKEN_TTL"])
+	if err != nil || ttl <= 0 {
+		errs = append(errs, fmt.Errorf("JWT_TOKEN_TTL must be a positive duration, got %q", values["JWT_TOKEN_TTL"]))
+	}
+	cfg.TokenTTL = ttl
+
+	refreshTTL, err := time.ParseDuration(values["REFRESH_TOKEN_TTL"])
+	if err != nil || refreshTTL <= ttl {
+		errs = append(errs, fmt.Errorf("REFRESH_TOKEN_TTL must be a duration longer than JWT_TOKEN_TTL, got %q", values["REFRESH_TOKEN_TTL"]))
+	}
+	cfg.RefreshTokenTTL = refreshTTL
+
+	cfg.Workflow = domain.DefaultWorkflow
+	if spec := values["TASK_WORKFLOW"]; spec != "" {
+		workflow, err := domain.ParseWorkflow(spec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("TASK_WORKFLOW: %w", err))
+		}
+		cfg.Workflow = workflow
+	}
+
+	requireIfMatch, err := strconv.ParseBool(values["REQUIRE_IF_MATCH"])
+	if err != nil {
+		errs = append(errs, fmt.Errorf("REQUIRE_IF_MATCH must be a boolean, got %q", values["REQUIRE_IF_MATCH"]))
+	}
+	cfg.RequireIfMatch = requireIfMatch
+
+	retention, err := time.ParseDuration(values["TRASH_RETENTION"])
+	if err != nil || retention <= 0 {
+		errs = append(errs, fmt.Errorf("TRASH_RETENTION must be a positive duration, got %q", values["TRASH_RETENTION"]))
+	}
+	cfg.TrashRetention = retention
+
+	purgeInterval, err := time.ParseDuration(values["TRASH_PURGE_INTERVAL"])
+	if err != nil || purgeInterval <= 0 {
+		errs = append(errs, fmt.Errorf("TRASH_PURGE_INTERVAL must be a positive duration, got %q", values["TRASH_PURGE_INTERVAL"]))
+	}
+	cfg.TrashPurgeInterval = purgeInterval
+
+	recurrenceInterval, err := time.ParseDuration(values["RECURRENCE_INTERVAL"])
+	if err != nil || recurrenceInterval <= 0 {
+		errs = append(errs, fmt.Errorf("RECURRENCE_INTERVAL must be a positive duration, got %q", values["RECURRENCE_INTERVAL"]))
+	}
+	cfg.RecurrenceInterval = recurrenceInterval
+
+	for env, duration := range map[string]*time.Duration{
+		"REMINDER_INTERVAL":         &cfg.ReminderInterval,
+		"REMINDER_OVERDUE_LOOKBACK": &cfg.ReminderPolicy.OverdueLookback,
+	} {
+		d, err := time.ParseDuration(values[env])
+		if err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be a positive duration, got %q", env, values[env]))
+		}
+		*duration = d
+	}
+	for _, window := range strings.Split(values["REMINDER_WINDOWS"], ",") {
+		if window = strings.TrimSpace(window); window == "" {
+			continue
+		}
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("REMINDER_WINDOWS: %q is not a positive duration", window))
+			continue
+		}
+		cfg.ReminderPolicy.Windows = append(cfg.ReminderPolicy.Windows, d)
+	}
+	switch cfg.ReminderNotifier {
+	case "log":
+	case "webhook":
+		if cfg.ReminderWebhookURL == "" {
+			errs = append(errs, errors.New("REMINDER_WEBHOOK_URL is required for the webhook notifier"))
+		}
+	case "smtp":
+		if cfg.SMTPAddr == "" || cfg.SMTPFrom == "" || cfg.SMTPTo == "" {
+			errs = append(errs, errors.New("SMTP_ADDR, SMTP_FROM and SMTP_TO are required for the smtp notifier"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("REMINDER_NOTIFIER must be log, webhook or smtp, got %q", cfg.ReminderNotifier))
+	}
+
+	for _, proxy := range strings.Split(values["TRUSTED_PROXIES"], ",") {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES: %q is not an IP address or CIDR", proxy))
+			continue
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, proxy)
+	}
+
+	minLength, err := strconv.Atoi(values["PASSWORD_MIN_LENGTH"])
+	if err != nil || minLength < 1 || minLength > 72 {
+		errs = append(errs, fmt.Errorf("PASSWORD_MIN_LENGTH must be a number between 1 and 72, got %q", values["PASSWORD_MIN_LENGTH"]))
+	}
+	cfg.PasswordPolicy.MinLength = minLength
+	for _, class := range strings.Split(values["PASSWORD_REQUIRE"], ",") {
+		switch strings.TrimSpace(class) {
+		case "":
+		case "upper":
+			cfg.PasswordPolicy.RequireUpper = true
+		case "lower":
+			cfg.PasswordPolicy.RequireLower = true
+		case "digit":
+			cfg.PasswordPolicy.RequireDigit = true
+		case "symbol":
+			cfg.PasswordPolicy.RequireSymbol = true
+		default:
+			errs = append(errs, fmt.Errorf("PASSWORD_REQUIRE: unknown character class %q", class))
+		}
+	}
+
+	for env, threshold := range map[string]*int{
+		"LOGIN_MAX_FAILURES":    &cfg.LockoutPolicy.Threshold,
+		"LOGIN_IP_MAX_FAILURES": &cfg.LockoutPolicy.IPThreshold,
+	} {
+		n, err := strconv.Atoi(values[env])
+		if err != nil || n < 0 {
+			errs = append(errs, fmt.Errorf("%s must be a non-negative number, got %q", env, values[env]))
+		}
+		*threshold = n
+	}
+	for env, duration := range map[string]*time.Duration{
+		"LOGIN_LOCKOUT":        &cfg.LockoutPolicy.BaseDelay,
+		"LOGIN_LOCKOUT_MAX":    &cfg.LockoutPolicy.MaxDelay,
+		"LOGIN_FAILURE_WINDOW": &cfg.LockoutPolicy.Window,
+	} {
+		d, err := time.ParseDuration(values[env])
+		if err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be a positive duration, got %q", env, values[env]))
+		}
+		*duration = d
+	}
+	if cfg.LockoutPolicy.MaxDelay < cfg.LockoutPolicy.BaseDelay {
+		errs = append(errs, fmt.Errorf("LOGIN_LOCKOUT_MAX must not be shorter than LOGIN_LOCKOUT"))
+	}
+
+	cfg.RateLimits = make(map[string]domain.RateLimit)
+	for group, env := range map[string]string{"public": "RATE_LIMIT_PUBLIC", "api": "RATE_LIMIT_API"} {
+		limit, err := domain.ParseRateLimit(values[env])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", env, err))
+		}
+		cfg.RateLimits[group] = limit
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+func readEnvFile(path string) (map[string]string, error) {
+	explicit := path != ""
+	if !explicit {
+		path = ".env"
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading env file: %w", err)
+	}
+	defer file.Close()
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNo)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading env file: %w", err)
+	}
+
+	return values, nil
+}
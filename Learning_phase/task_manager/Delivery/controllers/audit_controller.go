@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditController struct {
+	auditUsecase usecases.AuditUsecase
+}
+
+func NewAuditController(auditUsecase usecases.AuditUsecase) *AuditController {
+	return &AuditController{auditUsecase: auditUsecase}
+}
+
+func (ac *AuditController) GetEntries(c *gin.Context) {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	entries, err := ac.auditUsecase.GetEntries(c.Request.Context(), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+func parseAuditQuery(c *gin.Context) (domain.AuditQuery, error) {
+	query := domain.AuditQuery{
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return query, domain.Validation("limit must be a positive integer")
+		}
+		query.Limit = n
+	}
+
+	for param, target := range map[string]**time.Time{"from": &query.From, "to": &query.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parse := parseDate
+		if param == "to" {
+			parse = parseDateUntil
+		}
+		t, err := parse(value)
+		if err != nil {
+			return query, domain.Validation(param + " must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		*target = &t
+	}
+
+	return query, nil
+}
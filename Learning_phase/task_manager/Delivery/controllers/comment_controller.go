@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"net/http"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentController struct {
+	commentUsecase usecases.CommentUsecase
+}
+
+func NewCommentController(commentUsecase usecases.CommentUsecase) *CommentController {
+	return &CommentController{commentUsecase: commentUsecase}
+}
+
+func (cc *CommentController) GetComments(c *gin.Context) {
+	comments, err := cc.commentUsecase.GetComments(c.Request.Context(), actorFromContext(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comments": comments})
+}
+
+func (cc *CommentController) AddComment(c *gin.Context) {
+	var req domain.CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	comment, err := cc.commentUsecase.AddComment(c.Request.Context(), actorFromContext(c), c.Param("id"), req.Body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+func (cc *CommentController) EditComment(c *gin.Context) {
+	var req domain.CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	comment, err := cc.commentUsecase.EditComment(c.Request.Context(), actorFromContext(c), c.Param("id"), c.Param("comment_id"), req.Body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+func (cc *CommentController) DeleteComment(c *gin.Context) {
+	err := cc.commentUsecase.DeleteComment(c.Request.Context(), actorFromContext(c), c.Param("id"), c.Param("comment_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
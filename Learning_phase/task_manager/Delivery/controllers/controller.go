@@ -1,99 +1,465 @@
 package controllers
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"task_manager/Domain"
+	"task_manager/Infrastructure"
 	"task_manager/Usecases"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type TaskController struct {
-	taskUsecase usecases.TaskUsecase
+	taskUsecase    usecases.TaskUsecase
+	requireIfMatch bool
 }
 
 type UserController struct {
 	userUsecase usecases.UserUsecase
 }
 
-func NewTaskController(taskUsecase usecases.TaskUsecase) *TaskController {
-	return &TaskController{taskUsecase: taskUsecase}
+type KeyController struct {
+	jwtService *infrastructure.JWTService
+}
+
+func NewTaskController(taskUsecase usecases.TaskUsecase, requireIfMatch bool) *TaskController {
+	return &TaskController{taskUsecase: taskUsecase, requireIfMatch: requireIfMatch}
 }
 
 func NewUserController(userUsecase usecases.UserUsecase) *UserController {
 	return &UserController{userUsecase: userUsecase}
 }
 
+func NewKeyController(jwtService *infrastructure.JWTService) *KeyController {
+	return &KeyController{jwtService: jwtService}
+}
+
+func actorFromContext(c *gin.Context) domain.Actor {
+	return domain.Actor{
+		UserID:      c.GetString("user_id"),
+		Username:    c.GetString("username"),
+		Role:        c.GetString("role"),
+		Permissions: c.GetStringSlice("permissions"),
+		ProjectID:   c.GetString("project_id"),
+	}
+}
+
 func (tc *TaskController) GetTasks(c *gin.Context) {
-	tasks, err := tc.taskUsecase.GetAllTasks()
+	query, err := parseTaskQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+
+	page, err := tc.taskUsecase.GetAllTasks(c.Request.Context(), actorFromContext(c), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func parseTaskQuery(c *gin.Context) (domain.TaskQuery, error) {
+	query := domain.TaskQuery{
+		Status:    c.Query("status"),
+		Priority:  c.Query("priority"),
+		Title:     c.Query("title"),
+		ProjectID: c.Query("project"),
+		ParentID:  c.Query("parent"),
+		BlockedBy: c.Query("blocked_by"),
+		SeriesID:  c.Query("series"),
+		After:     c.Query("after"),
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		query.SortBy = strings.TrimPrefix(sort, "-")
+		query.SortDesc = strings.HasPrefix(sort, "-")
+	}
+
+	if labels := c.Query("label"); labels != "" {
+		for _, label := range strings.Split(labels, ",") {
+			if label = domain.NormalizeLabelName(label); label != "" {
+				query.Labels = append(query.Labels, label)
+			}
+		}
+	}
+	switch match := c.DefaultQuery("label_match", domain.LabelMatchAny); match {
+	case domain.LabelMatchAny, domain.LabelMatchAll:
+		query.LabelMatch = match
+	default:
+		return query, domain.Validation("label_match must be any or all")
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return query, domain.Validation("limit must be a positive integer")
+		}
+		query.Limit = n
+	}
+
+	for param, target := range map[string]**time.Time{"due_after": &query.DueAfter, "due_before": &query.DueBefore} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parse := parseDate
+		if param == "due_before" {
+			parse = parseDateUntil
+		}
+		t, err := parse(value)
+		if err != nil {
+			return query, domain.Validation(param + " must be an RFC 3339 timestamp or YYYY-MM-DD date")
+		}
+		*target = &t
+	}
+
+	return query, nil
+}
+
+// editScope reads the scope parameter, which decides whether an edit of a
+// recurring task applies to this occurrence only or to all future ones.
+func editScope(c *gin.Context) (string, error) {
+	switch scope := c.DefaultQuery("scope", domain.EditScopeThis); scope {
+	case domain.EditScopeThis, domain.EditScopeFuture:
+		return scope, nil
+	default:
+		return "", domain.Validation("scope must be this or future")
+	}
+}
+
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// parseDateUntil parses an inclusive upper bound: a bare date covers the
+// whole of that day.
+func parseDateUntil(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
 }
 
 func (tc *TaskController) GetTask(c *gin.Context) {
 	id := c.Param("id")
-	task, err := tc.taskUsecase.GetTaskByID(id)
+	task, err := tc.taskUsecase.GetTaskByID(c.Request.Context(), actorFromContext(c), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		c.Error(err)
+		return
+	}
+
+	if ifNoneMatch(c, taskETag(task)) {
+		c.Header("ETag", taskETag(task))
+		c.Status(http.StatusNotModified)
 		return
 	}
-	c.JSON(http.StatusOK, task)
+	writeTask(c, http.StatusOK, task)
 }
 
 func (tc *TaskController) CreateTask(c *gin.Context) {
 	var task domain.Task
 	if err := c.ShouldBindJSON(&task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.Validation(err.Error()))
 		return
 	}
 
-	createdTask, err := tc.taskUsecase.CreateTask(task)
+	createdTask, err := tc.taskUsecase.CreateTask(c.Request.Context(), actorFromContext(c), task)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusCreated, createdTask)
+	writeTask(c, http.StatusCreated, createdTask)
 }
 
 func (tc *TaskController) UpdateTask(c *gin.Context) {
 	id := c.Param("id")
 	var task domain.Task
 	if err := c.ShouldBindJSON(&task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	scope, err := editScope(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	updatedTask, err := tc.taskUsecase.UpdateTask(c.Request.Context(), actorFromContext(c), id, task, version, scope)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeTask(c, http.StatusOK, updatedTask)
+}
+
+func (tc *TaskController) PatchTask(c *gin.Context) {
+	var format domain.PatchFormat
+	switch c.ContentType() {
+	case string(domain.MergePatch), "application/json":
+		format = domain.MergePatch
+	case string(domain.JSONPatch):
+		format = domain.JSONPatch
+	default:
+		c.Error(domain.NewError(domain.ErrUnsupportedMediaType, "Content-Type must be application/merge-patch+json or application/json-patch+json"))
+		return
+	}
+
+	scope, err := editScope(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	document, err := c.GetRawData()
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	updatedTask, err := tc.taskUsecase.UpdateTask(id, task)
+	task, err := tc.taskUsecase.PatchTask(c.Request.Context(), actorFromContext(c), c.Param("id"), format, document, version, scope)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, updatedTask)
+	writeTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) TransitionTask(c *gin.Context) {
+	var req domain.TransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := tc.taskUsecase.TransitionTask(c.Request.Context(), actorFromContext(c), c.Param("id"), req.Status, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeTask(c, http.StatusOK, task)
 }
 
 func (tc *TaskController) DeleteTask(c *gin.Context) {
 	id := c.Param("id")
-	err := tc.taskUsecase.DeleteTask(id)
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	err := tc.taskUsecase.DeleteTask(c.Request.Context(), actorFromContext(c), id, version)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		c.Error(err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
 }
 
+func (tc *TaskController) GetDeletedTasks(c *gin.Context) {
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, err := tc.taskUsecase.GetDeletedTasks(c.Request.Context(), actorFromContext(c), query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (tc *TaskController) RestoreTask(c *gin.Context) {
+	task, err := tc.taskUsecase.RestoreTask(c.Request.Context(), actorFromContext(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) SetParent(c *gin.Context) {
+	var req domain.SetParentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := tc.taskUsecase.SetParent(c.Request.Context(), actorFromContext(c), c.Param("id"), req.ParentID, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) RemoveParent(c *gin.Context) {
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := tc.taskUsecase.SetParent(c.Request.Context(), actorFromContext(c), c.Param("id"), "", version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) AddBlocker(c *gin.Context) {
+	var req domain.AddBlockerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := tc.taskUsecase.AddBlocker(c.Request.Context(), actorFromContext(c), c.Param("id"), req.TaskID, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) RemoveBlocker(c *gin.Context) {
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := tc.taskUsecase.RemoveBlocker(c.Request.Context(), actorFromContext(c), c.Param("id"), c.Param("blocker_id"), version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) AttachLabel(c *gin.Context) {
+	var req domain.AttachLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := tc.taskUsecase.AttachLabel(c.Request.Context(), actorFromContext(c), c.Param("id"), req.Label, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) DetachLabel(c *gin.Context) {
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := tc.taskUsecase.DetachLabel(c.Request.Context(), actorFromContext(c), c.Param("id"), c.Param("label"), version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) SetRecurrence(c *gin.Context) {
+	var rule domain.RecurrenceRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := tc.taskUsecase.SetRecurrence(c.Request.Context(), actorFromContext(c), c.Param("id"), rule, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) StopRecurrence(c *gin.Context) {
+	version, ok := tc.ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := tc.taskUsecase.StopRecurrence(c.Request.Context(), actorFromContext(c), c.Param("id"), version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) GetTaskGraph(c *gin.Context) {
+	graph, err := tc.taskUsecase.GetTaskGraph(c.Request.Context(), actorFromContext(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
+
 func (uc *UserController) Register(c *gin.Context) {
 	var req domain.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.Validation(err.Error()))
 		return
 	}
 
-	user, err := uc.userUsecase.Register(req.Username, req.Password)
+	user, err := uc.userUsecase.Register(c.Request.Context(), req.Username, req.Password)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -103,26 +469,144 @@ func (uc *UserController) Register(c *gin.Context) {
 func (uc *UserController) Login(c *gin.Context) {
 	var req domain.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(domain.Validation(err.Error()))
 		return
 	}
 
-	user, token, err := uc.userUsecase.Login(req.Username, req.Password)
+	user, tokens, err := uc.userUsecase.Login(c.Request.Context(), req.Username, req.Password, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": token, "user": user})
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          user,
+	})
+}
+
+func (uc *UserController) Refresh(c *gin.Context) {
+	var req domain.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	tokens, err := uc.userUsecase.RefreshToken(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (uc *UserController) Logout(c *gin.Context) {
+	var req domain.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	err := uc.userUsecase.Logout(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
 func (uc *UserController) Promote(c *gin.Context) {
 	username := c.Param("username")
-	err := uc.userUsecase.PromoteUser(username)
+	err := uc.userUsecase.PromoteUser(c.Request.Context(), actorFromContext(c), username)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "User promoted to admin successfully"})
 }
+
+func (uc *UserController) Demote(c *gin.Context) {
+	username := c.Param("username")
+	err := uc.userUsecase.DemoteUser(c.Request.Context(), actorFromContext(c), username)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User demoted successfully"})
+}
+
+func (uc *UserController) AssignRole(c *gin.Context) {
+	var req domain.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	err := uc.userUsecase.AssignRole(c.Request.Context(), actorFromContext(c), c.Param("username"), req.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role assigned successfully"})
+}
+
+func (uc *UserController) GetLockout(c *gin.Context) {
+	status, err := uc.userUsecase.GetLockout(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (uc *UserController) Unlock(c *gin.Context) {
+	err := uc.userUsecase.UnlockUser(c.Request.Context(), actorFromContext(c), c.Param("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unlocked successfully"})
+}
+
+func (kc *KeyController) JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"keys": kc.jwtService.Keys().JWKS()})
+}
+
+func (kc *KeyController) Rotate(c *gin.Context) {
+	var req struct {
+		KID string `json:"kid"`
+		Alg string `json:"alg"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	keys := kc.jwtService.Keys()
+	kid := req.KID
+	var err error
+	if kid != "" {
+		err = keys.Activate(kid)
+	} else {
+		kid, err = keys.Rotate(req.Alg)
+	}
+	if errors.Is(err, infrastructure.ErrKeySetNotSaved) {
+		c.Error(domain.Internal(err))
+		return
+	}
+	if err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Signing key rotated successfully", "kid": kid})
+}
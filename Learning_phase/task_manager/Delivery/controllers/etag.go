@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+	"task_manager/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func taskETag(task domain.Task) string {
+	return `"` + strconv.FormatInt(task.Version, 10) + `"`
+}
+
+func writeTask(c *gin.Context, status int, task domain.Task) {
+	c.Header("ETag", taskETag(task))
+	c.JSON(status, task)
+}
+
+// ifMatchVersion returns the task version demanded by If-Match, or
+// domain.AnyVersion when the write is unconditional. "0" is accepted: it is
+// the ETag of tasks stored before versioning. It records the error on the
+// context and returns false when the request cannot proceed.
+func (tc *TaskController) ifMatchVersion(c *gin.Context) (int64, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		if tc.requireIfMatch {
+			c.Error(domain.NewError(domain.ErrPreconditionRequired, "If-Match header required"))
+			return 0, false
+		}
+		return domain.AnyVersion, true
+	}
+	if header == "*" {
+		return domain.AnyVersion, true
+	}
+	if strings.HasPrefix(header, "W/") {
+		c.Error(domain.NewError(domain.ErrPreconditionFailed, "weak entity tags cannot be used with If-Match"))
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || version < 0 || !strings.HasPrefix(header, `"`) || !strings.HasSuffix(header, `"`) {
+		c.Error(domain.Validation(`If-Match must be "*" or a single entity tag`))
+		return 0, false
+	}
+	return version, true
+}
+
+func ifNoneMatch(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const readinessCheckTimeout = 2 * time.Second
+
+type HealthCheck func(ctx context.Context) error
+
+type HealthController struct {
+	storage  string
+	checks   map[string]HealthCheck
+	draining atomic.Bool
+}
+
+func NewHealthController(storage string, checks map[string]HealthCheck) *HealthController {
+	return &HealthController{storage: storage, checks: checks}
+}
+
+// Drain makes the readiness probe fail so load balancers stop sending new
+// requests while the server shuts down.
+func (hc *HealthController) Drain() {
+	hc.draining.Store(true)
+}
+
+func (hc *HealthController) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (hc *HealthController) Readiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	ready := !hc.draining.Load()
+	results := make(map[string]string, len(hc.checks))
+	for name, check := range hc.checks {
+		if err := check(ctx); err != nil {
+			log.Printf("readiness check %s: %v", name, err)
+			results[name] = "unavailable"
+			ready = false
+			continue
+		}
+		results[name] = "ok"
+	}
+
+	status, body := http.StatusOK, gin.H{"status": "ok", "storage": hc.storage, "checks": results}
+	if !ready {
+		status = http.StatusServiceUnavailable
+		body["status"] = "unavailable"
+	}
+	if hc.draining.Load() {
+		body["status"] = "draining"
+	}
+	c.JSON(status, body)
+}
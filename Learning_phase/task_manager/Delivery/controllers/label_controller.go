@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"net/http"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelController struct {
+	labelUsecase usecases.LabelUsecase
+}
+
+func NewLabelController(labelUsecase usecases.LabelUsecase) *LabelController {
+	return &LabelController{labelUsecase: labelUsecase}
+}
+
+func (lc *LabelController) GetLabels(c *gin.Context) {
+	labels, err := lc.labelUsecase.GetLabels(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"labels": labels})
+}
+
+func (lc *LabelController) CreateLabel(c *gin.Context) {
+	var req domain.LabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	label, err := lc.labelUsecase.CreateLabel(c.Request.Context(), actorFromContext(c), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, label)
+}
+
+func (lc *LabelController) UpdateLabel(c *gin.Context) {
+	var req domain.LabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	label, err := lc.labelUsecase.UpdateLabel(c.Request.Context(), actorFromContext(c), c.Param("label_id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, label)
+}
+
+func (lc *LabelController) DeleteLabel(c *gin.Context) {
+	err := lc.labelUsecase.DeleteLabel(c.Request.Context(), actorFromContext(c), c.Param("label_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Label deleted successfully"})
+}
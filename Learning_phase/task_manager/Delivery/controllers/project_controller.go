@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"net/http"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectController struct {
+	projectUsecase usecases.ProjectUsecase
+}
+
+func NewProjectController(projectUsecase usecases.ProjectUsecase) *ProjectController {
+	return &ProjectController{projectUsecase: projectUsecase}
+}
+
+func (pc *ProjectController) GetProjects(c *gin.Context) {
+	projects, err := pc.projectUsecase.GetProjects(c.Request.Context(), actorFromContext(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"projects": projects})
+}
+
+func (pc *ProjectController) GetProject(c *gin.Context) {
+	project, err := pc.projectUsecase.GetProject(c.Request.Context(), actorFromContext(c), c.Param("pid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (pc *ProjectController) CreateProject(c *gin.Context) {
+	var req domain.CreateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	project, err := pc.projectUsecase.CreateProject(c.Request.Context(), actorFromContext(c), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, project)
+}
+
+func (pc *ProjectController) UpdateProject(c *gin.Context) {
+	var req domain.UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	project, err := pc.projectUsecase.UpdateProject(c.Request.Context(), actorFromContext(c), c.Param("pid"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (pc *ProjectController) DeleteProject(c *gin.Context) {
+	err := pc.projectUsecase.DeleteProject(c.Request.Context(), actorFromContext(c), c.Param("pid"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
+}
+
+func (pc *ProjectController) SetMember(c *gin.Context) {
+	var req domain.ProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	project, err := pc.projectUsecase.SetMember(c.Request.Context(), actorFromContext(c), c.Param("pid"), c.Param("username"), req.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+func (pc *ProjectController) RemoveMember(c *gin.Context) {
+	project, err := pc.projectUsecase.RemoveMember(c.Request.Context(), actorFromContext(c), c.Param("pid"), c.Param("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
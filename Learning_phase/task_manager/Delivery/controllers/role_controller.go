@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"net/http"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleController struct {
+	roleUsecase usecases.RoleUsecase
+}
+
+func NewRoleController(roleUsecase usecases.RoleUsecase) *RoleController {
+	return &RoleController{roleUsecase: roleUsecase}
+}
+
+func (rc *RoleController) GetRoles(c *gin.Context) {
+	roles, err := rc.roleUsecase.GetRoles(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"roles": roles, "permissions": domain.AllPermissions})
+}
+
+func (rc *RoleController) CreateRole(c *gin.Context) {
+	var role domain.Role
+	if err := c.ShouldBindJSON(&role); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	createdRole, err := rc.roleUsecase.CreateRole(c.Request.Context(), role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, createdRole)
+}
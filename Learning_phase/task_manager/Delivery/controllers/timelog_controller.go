@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"task_manager/Domain"
+	"task_manager/Usecases"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TimeLogController struct {
+	timeLogUsecase usecases.TimeLogUsecase
+}
+
+func NewTimeLogController(timeLogUsecase usecases.TimeLogUsecase) *TimeLogController {
+	return &TimeLogController{timeLogUsecase: timeLogUsecase}
+}
+
+func (tc *TimeLogController) GetTaskTime(c *gin.Context) {
+	report, err := tc.timeLogUsecase.GetTaskTime(c.Request.Context(), actorFromContext(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (tc *TimeLogController) LogTime(c *gin.Context) {
+	var req domain.TimeLogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	log, err := tc.timeLogUsecase.LogTime(c.Request.Context(), actorFromContext(c), c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, log)
+}
+
+func (tc *TimeLogController) DeleteTimeLog(c *gin.Context) {
+	err := tc.timeLogUsecase.DeleteTimeLog(c.Request.Context(), actorFromContext(c), c.Param("id"), c.Param("log_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Time log deleted successfully"})
+}
+
+func (tc *TimeLogController) StartTimer(c *gin.Context) {
+	var req domain.TimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	log, err := tc.timeLogUsecase.StartTimer(c.Request.Context(), actorFromContext(c), c.Param("id"), req.Note)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, log)
+}
+
+func (tc *TimeLogController) StopTimer(c *gin.Context) {
+	var req domain.TimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(domain.Validation(err.Error()))
+		return
+	}
+
+	log, err := tc.timeLogUsecase.StopTimer(c.Request.Context(), actorFromContext(c), c.Param("id"), req.Note)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, log)
+}
+
+func (tc *TimeLogController) GetRunningTimer(c *gin.Context) {
+	log, err := tc.timeLogUsecase.GetRunningTimer(c.Request.Context(), actorFromContext(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, log)
+}
+
+func (tc *TimeLogController) GetUserTime(c *gin.Context) {
+	var from, to *time.Time
+	for param, target := range map[string]**time.Time{"from": &from, "to": &to} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parse := parseDate
+		if param == "to" {
+			parse = parseDateUntil
+		}
+		t, err := parse(value)
+		if err != nil {
+			c.Error(domain.Validation(param + " must be an RFC 3339 timestamp or YYYY-MM-DD date"))
+			return
+		}
+		*target = &t
+	}
+
+	report, err := tc.timeLogUsecase.GetUserTime(c.Request.Context(), actorFromContext(c), c.Query("user"), from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -2,49 +2,247 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"task_manager/Config"
 	"task_manager/Delivery/controllers"
 	"task_manager/Delivery/routers"
 	"task_manager/Infrastructure"
 	"task_manager/Repositories"
 	"task_manager/Usecases"
 	"time"
+	_ "time/tzdata"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 func main() {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	mongoURI := "mongodb://localhost:27017"
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	cfg, err := config.Load(os.Args[1:])
 	if err != nil {
-		log.Fatal("Failed to connect to MongoDB:", err)
+		log.Fatal(err)
 	}
-	defer client.Disconnect(ctx)
 
-	if err := client.Ping(ctx, nil); err != nil {
-		log.Fatal("Failed to ping MongoDB:", err)
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	log.Println("Connected to MongoDB successfully!")
+	var taskRepo repositories.TaskRepository
+	var userRepo repositories.UserRepository
+	var refreshTokenRepo repositories.RefreshTokenRepository
+	var roleRepo repositories.RoleRepository
+	var auditRepo repositories.AuditRepository
+	var commentRepo repositories.CommentRepository
+	var projectRepo repositories.ProjectRepository
+	var labelRepo repositories.LabelRepository
+	var timeLogRepo repositories.TimeLogRepository
+	var reminderRepo repositories.ReminderRepository
+	var throttleRepo repositories.LoginThrottleRepository
+	var client *mongo.Client
+	checks := map[string]controllers.HealthCheck{}
 
-	taskRepo := repositories.NewTaskRepository(client, "taskdb", "tasks")
-	userRepo := repositories.NewUserRepository(client, "taskdb", "users")
+	switch cfg.Storage {
+	case "memory":
+		log.Println("Using in-memory storage")
+		taskRepo = repositories.NewMemoryTaskRepository()
+		userRepo = repositories.NewMemoryUserRepository()
+		refreshTokenRepo = repositories.NewMemoryRefreshTokenRepository()
+		roleRepo = repositories.NewMemoryRoleRepository()
+		auditRepo = repositories.NewMemoryAuditRepository()
+		commentRepo = repositories.NewMemoryCommentRepository()
+		projectRepo = repositories.NewMemoryProjectRepository()
+		labelRepo = repositories.NewMemoryLabelRepository()
+		timeLogRepo = repositories.NewMemoryTimeLogRepository()
+		reminderRepo = repositories.NewMemoryReminderRepository()
+		throttleRepo = repositories.NewMemoryLoginThrottleRepository()
+		checks["memory"] = func(context.Context) error { return nil }
+	case "mongo":
+		client, err = connectMongo(cfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Println("Connected to MongoDB successfully!")
+
+		taskRepo = repositories.NewTaskRepository(client, cfg.DatabaseName, cfg.TaskCollection, cfg.DBTimeout)
+		userRepo = repositories.NewUserRepository(client, cfg.DatabaseName, cfg.UserCollection, cfg.DBTimeout)
+		refreshTokenRepo = repositories.NewRefreshTokenRepository(client, cfg.DatabaseName, cfg.RefreshTokenCollection, cfg.DBTimeout)
+		roleRepo = repositories.NewRoleRepository(client, cfg.DatabaseName, cfg.RoleCollection, cfg.DBTimeout)
+		auditRepo = repositories.NewAuditRepository(client, cfg.DatabaseName, cfg.AuditCollection, cfg.DBTimeout)
+		commentRepo = repositories.NewCommentRepository(client, cfg.DatabaseName, cfg.CommentCollection, cfg.DBTimeout)
+		projectRepo = repositories.NewProjectRepository(client, cfg.DatabaseName, cfg.ProjectCollection, cfg.DBTimeout)
+		labelRepo = repositories.NewLabelRepository(client, cfg.DatabaseName, cfg.LabelCollection, cfg.DBTimeout)
+		timeLogRepo = repositories.NewTimeLogRepository(client, cfg.DatabaseName, cfg.TimeLogCollection, cfg.DBTimeout)
+		reminderRepo = repositories.NewReminderRepository(client, cfg.DatabaseName, cfg.ReminderCollection, cfg.DBTimeout)
+		throttleRepo = repositories.NewLoginThrottleRepository(client, cfg.DatabaseName, cfg.LoginThrottleCollection, cfg.DBTimeout)
+		checks["mongo"] = func(ctx context.Context) error {
+			return client.Ping(ctx, readpref.Primary())
+		}
+	}
+
+	for _, ensureIndexes := range []func(context.Context) error{userRepo.EnsureIndexes, roleRepo.EnsureIndexes, taskRepo.EnsureIndexes, projectRepo.EnsureIndexes, commentRepo.EnsureIndexes, auditRepo.EnsureIndexes, labelRepo.EnsureIndexes, timeLogRepo.EnsureIndexes, reminderRepo.EnsureIndexes, throttleRepo.EnsureIndexes, refreshTokenRepo.EnsureIndexes} {
+		if err := ensureIndexes(ctx); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	passwordService := infrastructure.NewPasswordService()
-	jwtService := infrastructure.NewJWTService()
+	keys, err := loadKeySet(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	jwtService := infrastructure.NewJWTService(keys, cfg.TokenTTL, cfg.RefreshTokenTTL)
+
+	passwordPolicy := cfg.PasswordPolicy
+	if cfg.PasswordDenylistFile != "" {
+		passwordPolicy.Denylist, err = infrastructure.LoadPasswordDenylist(cfg.PasswordDenylistFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
-	taskUsecase := usecases.NewTaskUsecase(taskRepo)
-	userUsecase := usecases.NewUserUsecase(userRepo, passwordService, jwtService)
+	auditUsecase := usecases.NewAuditUsecase(auditRepo)
+	taskUsecase := usecases.NewTaskUsecase(taskRepo, labelRepo, projectRepo, auditUsecase, cfg.Workflow)
+	commentUsecase := usecases.NewCommentUsecase(commentRepo, taskRepo, userRepo, projectRepo, auditUsecase)
+	projectUsecase := usecases.NewProjectUsecase(projectRepo, taskRepo, userRepo, auditUsecase)
+	labelUsecase := usecases.NewLabelUsecase(labelRepo, taskRepo, auditUsecase)
+	timeLogUsecase := usecases.NewTimeLogUsecase(timeLogRepo, taskRepo, userRepo, projectRepo, auditUsecase)
+	roleUsecase := usecases.NewRoleUsecase(roleRepo)
+	userUsecase := usecases.NewUserUsecase(userRepo, refreshTokenRepo, throttleRepo, roleUsecase, auditUsecase, passwordService, jwtService, passwordPolicy, cfg.LockoutPolicy)
 
-	taskController := controllers.NewTaskController(taskUsecase)
+	var workers sync.WaitGroup
+	purger := usecases.NewTrashPurger(taskRepo, commentRepo, timeLogRepo, reminderRepo, cfg.TrashRetention, cfg.TrashPurgeInterval)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		purger.Run(ctx)
+	}()
+	scheduler := usecases.NewRecurrenceScheduler(taskRepo, auditUsecase, cfg.RecurrenceInterval)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		scheduler.Run(ctx)
+	}()
+	reminders := usecases.NewReminderWorker(taskRepo, userRepo, reminderRepo, newNotifier(cfg), cfg.ReminderPolicy, cfg.ReminderInterval)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		reminders.Run(ctx)
+	}()
+
+	taskController := controllers.NewTaskController(taskUsecase, cfg.RequireIfMatch)
+	commentController := controllers.NewCommentController(commentUsecase)
+	projectController := controllers.NewProjectController(projectUsecase)
+	labelController := controllers.NewLabelController(labelUsecase)
+	timeLogController := controllers.NewTimeLogController(timeLogUsecase)
 	userController := controllers.NewUserController(userUsecase)
+	roleController := controllers.NewRoleController(roleUsecase)
+	keyController := controllers.NewKeyController(jwtService)
+	auditController := controllers.NewAuditController(auditUsecase)
+	healthController := controllers.NewHealthController(cfg.Storage, checks)
+
+	authMiddleware := infrastructure.NewAuthMiddleware(jwtService, projectUsecase)
+	rateLimiter := infrastructure.NewRateLimiter(infrastructure.NewMemoryRateLimitStore(), cfg.RateLimits)
+
+	r, err := routers.SetupRouter(taskController, commentController, projectController, labelController, timeLogController, userController, roleController, keyController, auditController, healthController, authMiddleware, rateLimiter, cfg.TrustedProxies, cfg.RequestTimeout)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := &http.Server{Addr: cfg.Addr(), Handler: r}
 
-	authMiddleware := infrastructure.NewAuthMiddleware(jwtService)
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("Listening on %s", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
 
-	r := routers.SetupRouter(taskController, userController, authMiddleware)
-	r.Run(":8080")
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	case <-ctx.Done():
+	}
+	stop()
+
+	log.Println("Shutting down, draining in-flight requests...")
+	healthController.Drain()
+	// Keep serving while load balancers notice /readyz failing.
+	time.Sleep(cfg.DrainDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server did not shut down cleanly: %v", err)
+	}
+	workers.Wait()
+	if client != nil {
+		if err := client.Disconnect(shutdownCtx); err != nil {
+			log.Printf("Disconnecting from MongoDB: %v", err)
+		}
+	}
+	log.Println("Server stopped")
+}
+
+func connectMongo(cfg *config.Config) (*mongo.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DBTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoURI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+	return client, nil
+}
+
+func newNotifier(cfg *config.Config) infrastructure.Notifier {
+	switch cfg.ReminderNotifier {
+	case "webhook":
+		return infrastructure.NewWebhookNotifier(cfg.ReminderWebhookURL, cfg.RequestTimeout)
+	case "smtp":
+		return infrastructure.NewSMTPNotifier(infrastructure.SMTPConfig{
+			Addr:     cfg.SMTPAddr,
+			From:     cfg.SMTPFrom,
+			To:       cfg.SMTPTo,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			Timeout:  cfg.RequestTimeout,
+		})
+	default:
+		return infrastructure.NewLogNotifier()
+	}
+}
+
+func loadKeySet(cfg *config.Config) (*infrastructure.KeySet, error) {
+	if cfg.JWTKeysFile == "" {
+		return infrastructure.NewHMACKeySet(cfg.JWTSecret), nil
+	}
+
+	keys, err := infrastructure.LoadKeySet(cfg.JWTKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.JWTSecret != "" {
+		if err := keys.AddHMACKey(infrastructure.DefaultKeyID, cfg.JWTSecret); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.JWTSigningKID != "" {
+		if err := keys.SetSigningKey(cfg.JWTSigningKID); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := keys.SigningKey(); err != nil {
+		return nil, err
+	}
+	return keys, nil
 }
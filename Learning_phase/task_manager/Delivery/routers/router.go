@@ -2,28 +2,128 @@ package routers
 
 import (
 	"task_manager/Delivery/controllers"
+	"task_manager/Domain"
 	"task_manager/Infrastructure"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(taskController *controllers.TaskController, userController *controllers.UserController, authMiddleware *infrastructure.AuthMiddleware) *gin.Engine {
+func SetupRouter(taskController *controllers.TaskController, commentController *controllers.CommentController, projectController *controllers.ProjectController, labelController *controllers.LabelController, timeLogController *controllers.TimeLogController, userController *controllers.UserController, roleController *controllers.RoleController, keyController *controllers.KeyController, auditController *controllers.AuditController, healthController *controllers.HealthController, authMiddleware *infrastructure.AuthMiddleware, rateLimiter *infrastructure.RateLimiter, trustedProxies []string, requestTimeout time.Duration) (*gin.Engine, error) {
 	r := gin.Default()
+	// The client IP keys login lockouts and rate limits, so X-Forwarded-For
+	// only counts when it was set by one of our own proxies.
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	r.Use(infrastructure.ErrorHandler(), infrastructure.RequestTimeout(requestTimeout))
+
+	r.GET("/healthz", healthController.Liveness)
+	r.GET("/readyz", healthController.Readiness)
 
-	r.POST("/register", userController.Register)
-	r.POST("/login", userController.Login)
+	r.GET("/.well-known/jwks.json", keyController.JWKS)
 
-	r.GET("/tasks", authMiddleware.AuthRequired(), taskController.GetTasks)
-	r.GET("/tasks/:id", authMiddleware.AuthRequired(), taskController.GetTask)
+	public := r.Group("/")
+	public.Use(rateLimiter.Limit("public"))
+	{
+		public.POST("/register", userController.Register)
+		public.POST("/login", userController.Login)
+		public.POST("/token/refresh", userController.Refresh)
+		public.POST("/logout", userController.Logout)
+	}
 
-	admin := r.Group("/")
-	admin.Use(authMiddleware.AuthRequired(), authMiddleware.AdminOnly())
+	protected := r.Group("/")
+	protected.Use(authMiddleware.AuthRequired(), rateLimiter.Limit("api"))
 	{
-		admin.POST("/tasks", taskController.CreateTask)
-		admin.PUT("/tasks/:id", taskController.UpdateTask)
-		admin.DELETE("/tasks/:id", taskController.DeleteTask)
-		admin.PUT("/promote/:username", userController.Promote)
+		protected.GET("/tasks", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), taskController.GetTasks)
+		protected.GET("/tasks/:id", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), taskController.GetTask)
+		protected.POST("/tasks", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.CreateTask)
+		protected.PUT("/tasks/:id", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.UpdateTask)
+		protected.PATCH("/tasks/:id", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.PatchTask)
+		protected.POST("/tasks/:id/transitions", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.TransitionTask)
+		protected.DELETE("/tasks/:id", authMiddleware.RequirePermission(domain.PermTasksDeleteOwn, domain.PermTasksDeleteAny), taskController.DeleteTask)
+		protected.PUT("/tasks/:id/parent", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.SetParent)
+		protected.DELETE("/tasks/:id/parent", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.RemoveParent)
+		protected.POST("/tasks/:id/blockers", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.AddBlocker)
+		protected.DELETE("/tasks/:id/blockers/:blocker_id", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.RemoveBlocker)
+		protected.GET("/tasks/:id/graph", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), taskController.GetTaskGraph)
+		protected.POST("/tasks/:id/labels", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.AttachLabel)
+		protected.DELETE("/tasks/:id/labels/:label", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.DetachLabel)
+		protected.PUT("/tasks/:id/recurrence", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.SetRecurrence)
+		protected.DELETE("/tasks/:id/recurrence", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.StopRecurrence)
+		protected.GET("/tasks/trash", authMiddleware.RequirePermission(domain.PermTasksTrash), taskController.GetDeletedTasks)
+		protected.POST("/tasks/:id/restore", authMiddleware.RequirePermission(domain.PermTasksTrash), taskController.RestoreTask)
+
+		protected.GET("/tasks/:id/timelogs", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), timeLogController.GetTaskTime)
+		protected.POST("/tasks/:id/timelogs", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), timeLogController.LogTime)
+		protected.DELETE("/tasks/:id/timelogs/:log_id", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), timeLogController.DeleteTimeLog)
+		protected.POST("/tasks/:id/timer/start", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), timeLogController.StartTimer)
+		protected.POST("/tasks/:id/timer/stop", timeLogController.StopTimer)
+		protected.GET("/timer", timeLogController.GetRunningTimer)
+		protected.GET("/timelogs/totals", timeLogController.GetUserTime)
+
+		protected.GET("/tasks/:id/comments", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), commentController.GetComments)
+		protected.POST("/tasks/:id/comments", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), commentController.AddComment)
+		protected.PUT("/tasks/:id/comments/:comment_id", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), commentController.EditComment)
+		protected.DELETE("/tasks/:id/comments/:comment_id", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), commentController.DeleteComment)
+
+		protected.GET("/labels", labelController.GetLabels)
+		protected.POST("/labels", authMiddleware.RequirePermission(domain.PermLabelsManage), labelController.CreateLabel)
+		protected.PUT("/labels/:label_id", authMiddleware.RequirePermission(domain.PermLabelsManage), labelController.UpdateLabel)
+		protected.DELETE("/labels/:label_id", authMiddleware.RequirePermission(domain.PermLabelsManage), labelController.DeleteLabel)
+
+		protected.GET("/projects", projectController.GetProjects)
+		protected.POST("/projects", authMiddleware.RequirePermission(domain.PermProjectsCreate, domain.PermProjectsManage), projectController.CreateProject)
+		protected.GET("/projects/:pid", authMiddleware.RequireProjectRole(domain.ProjectRoleViewer), projectController.GetProject)
+		protected.PUT("/projects/:pid", authMiddleware.RequireProjectRole(domain.ProjectRoleOwner), projectController.UpdateProject)
+		protected.DELETE("/projects/:pid", authMiddleware.RequireProjectRole(domain.ProjectRoleOwner), projectController.DeleteProject)
+		protected.PUT("/projects/:pid/members/:username", authMiddleware.RequireProjectRole(domain.ProjectRoleOwner), projectController.SetMember)
+		protected.DELETE("/projects/:pid/members/:username", authMiddleware.RequireProjectRole(domain.ProjectRoleOwner), projectController.RemoveMember)
+
+		project := protected.Group("/projects/:pid", authMiddleware.RequireProjectRole(domain.ProjectRoleViewer))
+		{
+			project.GET("/tasks", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), taskController.GetTasks)
+			project.GET("/tasks/:id", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), taskController.GetTask)
+			project.POST("/tasks", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.CreateTask)
+			project.PUT("/tasks/:id", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.UpdateTask)
+			project.PATCH("/tasks/:id", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.PatchTask)
+			project.POST("/tasks/:id/transitions", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.TransitionTask)
+			project.DELETE("/tasks/:id", authMiddleware.RequirePermission(domain.PermTasksDeleteOwn, domain.PermTasksDeleteAny), taskController.DeleteTask)
+			project.PUT("/tasks/:id/parent", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.SetParent)
+			project.DELETE("/tasks/:id/parent", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.RemoveParent)
+			project.POST("/tasks/:id/blockers", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.AddBlocker)
+			project.DELETE("/tasks/:id/blockers/:blocker_id", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.RemoveBlocker)
+			project.GET("/tasks/:id/graph", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), taskController.GetTaskGraph)
+			project.POST("/tasks/:id/labels", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.AttachLabel)
+			project.DELETE("/tasks/:id/labels/:label", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.DetachLabel)
+			project.PUT("/tasks/:id/recurrence", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.SetRecurrence)
+			project.DELETE("/tasks/:id/recurrence", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), taskController.StopRecurrence)
+
+			project.GET("/tasks/:id/timelogs", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), timeLogController.GetTaskTime)
+			project.POST("/tasks/:id/timelogs", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), timeLogController.LogTime)
+			project.DELETE("/tasks/:id/timelogs/:log_id", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), timeLogController.DeleteTimeLog)
+			project.POST("/tasks/:id/timer/start", authMiddleware.RequirePermission(domain.PermTasksWriteOwn, domain.PermTasksWriteAny), timeLogController.StartTimer)
+			project.POST("/tasks/:id/timer/stop", timeLogController.StopTimer)
+
+			project.GET("/tasks/:id/comments", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), commentController.GetComments)
+			project.POST("/tasks/:id/comments", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), commentController.AddComment)
+			project.PUT("/tasks/:id/comments/:comment_id", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), commentController.EditComment)
+			project.DELETE("/tasks/:id/comments/:comment_id", authMiddleware.RequirePermission(domain.PermTasksReadOwn, domain.PermTasksReadAny), commentController.DeleteComment)
+		}
+
+		protected.PUT("/promote/:username", authMiddleware.RequirePermission(domain.PermUsersPromote), userController.Promote)
+		protected.PUT("/demote/:username", authMiddleware.RequirePermission(domain.PermUsersPromote), userController.Demote)
+		protected.PUT("/users/:username/role", authMiddleware.RequirePermission(domain.PermRolesManage), userController.AssignRole)
+		protected.GET("/users/:username/lockout", authMiddleware.RequirePermission(domain.PermUsersUnlock), userController.GetLockout)
+		protected.DELETE("/users/:username/lockout", authMiddleware.RequirePermission(domain.PermUsersUnlock), userController.Unlock)
+
+		protected.GET("/roles", authMiddleware.RequirePermission(domain.PermRolesManage), roleController.GetRoles)
+		protected.POST("/roles", authMiddleware.RequirePermission(domain.PermRolesManage), roleController.CreateRole)
+
+		protected.POST("/keys/rotate", authMiddleware.RequirePermission(domain.PermKeysRotate), keyController.Rotate)
+
+		protected.GET("/audit", authMiddleware.RequirePermission(domain.PermAuditRead), auditController.GetEntries)
 	}
 
-	return r
+	return r, nil
 }
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AuditTaskCreate     = "task.create"
+	AuditTaskUpdate     = "task.update"
+	AuditTaskPatch      = "task.patch"
+	AuditTaskTransition = "task.transition"
+	AuditTaskDelete     = "task.delete"
+	AuditTaskRestore    = "task.restore"
+	AuditTaskDependency = "task.dependency"
+	AuditTaskLabel      = "task.label"
+	AuditTaskRecurrence = "task.recurrence"
+	AuditUserRegister   = "user.register"
+	AuditUserLogout     = "user.logout"
+	AuditUserRole       = "user.role"
+	AuditUserLock       = "user.lock"
+	AuditUserUnlock     = "user.unlock"
+	AuditCommentCreate  = "comment.create"
+	AuditCommentUpdate  = "comment.update"
+	AuditCommentDelete  = "comment.delete"
+	AuditProjectCreate  = "project.create"
+	AuditProjectUpdate  = "project.update"
+	AuditProjectDelete  = "project.delete"
+	AuditProjectMember  = "project.member"
+	AuditLabelCreate    = "label.create"
+	AuditLabelUpdate    = "label.update"
+	AuditLabelDelete    = "label.delete"
+	AuditTimeLogCreate  = "timelog.create"
+	AuditTimeLogUpdate  = "timelog.update"
+	AuditTimeLogDelete  = "timelog.delete"
+)
+
+const (
+	AuditTargetTask    = "task"
+	AuditTargetUser    = "user"
+	AuditTargetComment = "comment"
+	AuditTargetProject = "project"
+	AuditTargetLabel   = "label"
+	AuditTargetTimeLog = "timelog"
+)
+
+type AuditEntry struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	ActorID       string             `json:"actor_id" bson:"actor_id"`
+	ActorUsername string             `json:"actor_username" bson:"actor_username"`
+	Action        string             `json:"action" bson:"action"`
+	TargetType    string             `json:"target_type" bson:"target_type"`
+	TargetID      string             `json:"target_id" bson:"target_id"`
+	Changes       []FieldChange      `json:"changes" bson:"changes"`
+	Timestamp     time.Time          `json:"timestamp" bson:"timestamp"`
+}
+
+type FieldChange struct {
+	Field  string      `json:"field" bson:"field"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+type AuditQuery struct {
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Comment struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TaskID         string             `json:"task_id" bson:"task_id"`
+	AuthorID       string             `json:"author_id" bson:"author_id"`
+	AuthorUsername string             `json:"author_username" bson:"author_username"`
+	Body           string             `json:"body" bson:"body"`
+	Mentions       []Mention          `json:"mentions" bson:"mentions"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      *time.Time         `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+}
+
+type Mention struct {
+	UserID   string `json:"user_id" bson:"user_id"`
+	Username string `json:"username" bson:"username"`
+}
+
+type CommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
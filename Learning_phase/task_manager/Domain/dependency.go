@@ -0,0 +1,38 @@
+package domain
+
+const (
+	EdgeBlocks  = "blocks"
+	EdgeSubtask = "subtask"
+)
+
+type SetParentRequest struct {
+	ParentID string `json:"parent_id" binding:"required"`
+}
+
+type AddBlockerRequest struct {
+	TaskID string `json:"task_id" binding:"required"`
+}
+
+// TaskGraph is the part of the dependency graph around Root: its blockers
+// and the tasks it blocks, transitively, plus its parents and subtasks.
+// Tasks the caller may not read appear with their ID only.
+type TaskGraph struct {
+	Root      string          `json:"root"`
+	Nodes     []TaskGraphNode `json:"nodes"`
+	Edges     []TaskGraphEdge `json:"edges"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+type TaskGraphNode struct {
+	ID     string `json:"id"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status,omitempty"`
+}
+
+// TaskGraphEdge points from a blocker to the task it blocks, or from a
+// parent to its subtask.
+type TaskGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
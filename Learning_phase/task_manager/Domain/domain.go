@@ -7,18 +7,120 @@ import (
 )
 
 type Task struct {
-	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Title       string             `json:"title" bson:"title"`
-	Description string             `json:"description" bson:"description"`
-	DueDate     time.Time          `json:"due_date" bson:"due_date"`
-	Status      string             `json:"status" bson:"status"`
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Title           string             `json:"title" bson:"title"`
+	Description     string             `json:"description" bson:"description"`
+	DueDate         time.Time          `json:"due_date" bson:"due_date"`
+	Status          string             `json:"status" bson:"status"`
+	Priority        string             `json:"priority" bson:"priority"`
+	EstimateMinutes int                `json:"estimate_minutes,omitempty" bson:"estimate_minutes,omitempty"`
+	StatusHistory   []StatusChange     `json:"status_history" bson:"status_history"`
+	CreatedBy       string             `json:"created_by" bson:"created_by"`
+	AssigneeID      string             `json:"assignee_id" bson:"assignee_id"`
+	ProjectID       string             `json:"project_id,omitempty" bson:"project_id,omitempty"`
+	ParentID        string             `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	BlockedBy       []string           `json:"blocked_by,omitempty" bson:"blocked_by,omitempty"`
+	Labels          []string           `json:"labels,omitempty" bson:"labels,omitempty"`
+	Recurrence      *Recurrence        `json:"recurrence,omitempty" bson:"recurrence,omitempty"`
+	Version         int64              `json:"version" bson:"version"`
+	DeletedAt       *time.Time         `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy       string             `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
+}
+
+var ErrVersionMismatch = NewError(ErrPreconditionFailed, "task was modified since the given version")
+
+// AnyVersion makes a task write unconditional. Version 0 is a real version:
+// tasks stored before versioning was introduced have no version field.
+const AnyVersion int64 = -1
+
+type TaskPatch struct {
+	Title           *string
+	Description     *string
+	DueDate         *time.Time
+	Status          *string
+	StatusHistory   []StatusChange
+	AssigneeID      *string
+	Priority        *string
+	EstimateMinutes *int
+	Recurrence      *Recurrence
+}
+
+func (p TaskPatch) IsEmpty() bool {
+	return p.Title == nil && p.Description == nil && p.DueDate == nil && p.Status == nil && p.AssigneeID == nil && p.Priority == nil && p.EstimateMinutes == nil
+}
+
+type PatchFormat string
+
+const (
+	MergePatch PatchFormat = "application/merge-patch+json"
+	JSONPatch  PatchFormat = "application/json-patch+json"
+)
+
+// Actor is the caller of a usecase. On routes nested under a project,
+// ProjectID is set and the task permissions are those of the caller's
+// project role; task operations are then confined to that project.
+type Actor struct {
+	UserID      string
+	Username    string
+	Role        string
+	Permissions []string
+	ProjectID   string
+}
+
+func (a Actor) Has(permission string) bool {
+	for _, p := range a.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type TaskQuery struct {
+	OwnerID string
+	Status  string
+	// ExcludeStatuses leaves out tasks in any of these statuses.
+	ExcludeStatuses []string
+	Priority        string
+	DueAfter        *time.Time
+	DueBefore       *time.Time
+	Title           string
+	ProjectID       string
+	// NoProject restricts the results to tasks outside any project.
+	NoProject bool
+	ParentID  string
+	BlockedBy string
+	SeriesID  string
+	// Labels matches tasks carrying any of the labels, or all of them when
+	// LabelMatch is LabelMatchAll.
+	Labels     []string
+	LabelMatch string
+	Deleted    bool
+	SortBy     string
+	SortDesc   bool
+	Limit      int
+	After      string
+	Cursor     *TaskCursor
+}
+
+type TaskCursor struct {
+	Value interface{}
+	ID    primitive.ObjectID
+}
+
+type TaskPage struct {
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type User struct {
-	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Username string             `json:"username" bson:"username"`
-	Password string             `json:"password,omitempty" bson:"password"`
-	Role     string             `json:"role" bson:"role"`
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Username        string             `json:"username" bson:"username"`
+	Password        string             `json:"password,omitempty" bson:"password"`
+	Role            string             `json:"role" bson:"role"`
+	FailedLogins    int                `json:"-" bson:"failed_logins,omitempty"`
+	LastFailedLogin *time.Time         `json:"-" bson:"last_failed_login,omitempty"`
+	LockedUntil     *time.Time         `json:"-" bson:"locked_until,omitempty"`
 }
 
 type LoginRequest struct {
@@ -30,3 +132,32 @@ type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type RefreshToken struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    string             `json:"user_id" bson:"user_id"`
+	FamilyID  string             `json:"family_id" bson:"family_id"`
+	TokenHash string             `json:"-" bson:"token_hash"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time          `json:"expires_at" bson:"expires_at"`
+	RevokedAt *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	// RevokeReason tells a token that was rotated, and so must never be seen
+	// again, from one that was revoked by logout or reuse detection.
+	RevokeReason string `json:"revoke_reason,omitempty" bson:"revoke_reason,omitempty"`
+}
+
+const (
+	RevokeRotated = "rotated"
+	RevokeLogout  = "logout"
+	RevokeReused  = "reuse_detected"
+)
+
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
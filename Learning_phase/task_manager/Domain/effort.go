@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+var ErrTimerRunning = NewError(ErrConflict, "a timer is already running")
+
+var TaskPriorities = []string{PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent}
+
+func IsTaskPriority(priority string) bool {
+	for _, p := range TaskPriorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeLog is a span of work a user spent on a task. A running timer is a
+// TimeLog with Running set and no End; it does not count towards totals
+// until it is stopped.
+type TimeLog struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TaskID          string             `json:"task_id" bson:"task_id"`
+	UserID          string             `json:"user_id" bson:"user_id"`
+	Username        string             `json:"username" bson:"username"`
+	Start           time.Time          `json:"start" bson:"start"`
+	End             *time.Time         `json:"end,omitempty" bson:"end,omitempty"`
+	DurationSeconds int64              `json:"duration_seconds" bson:"duration_seconds"`
+	Note            string             `json:"note,omitempty" bson:"note,omitempty"`
+	Running         bool               `json:"running" bson:"running"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// TimeLogRequest records finished work: either start and end, or a duration
+// such as "1h30m" with an optional start. Without a start the work is taken
+// to have ended now.
+type TimeLogRequest struct {
+	Start    *time.Time `json:"start"`
+	End      *time.Time `json:"end"`
+	Duration string     `json:"duration"`
+	Note     string     `json:"note"`
+}
+
+type TimerRequest struct {
+	Note string `json:"note"`
+}
+
+type TimeLogQuery struct {
+	TaskID string
+	UserID string
+	From   *time.Time
+	To     *time.Time
+}
+
+type TimeTotal struct {
+	TaskID       string `json:"task_id,omitempty" bson:"task_id,omitempty"`
+	UserID       string `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	Username     string `json:"username,omitempty" bson:"username,omitempty"`
+	TotalSeconds int64  `json:"total_seconds" bson:"total_seconds"`
+}
+
+type TaskTimeReport struct {
+	TaskID          string      `json:"task_id"`
+	EstimateMinutes int         `json:"estimate_minutes,omitempty"`
+	TotalSeconds    int64       `json:"total_seconds"`
+	ByUser          []TimeTotal `json:"by_user"`
+	TimeLogs        []TimeLog   `json:"timelogs"`
+}
+
+type UserTimeReport struct {
+	UserID       string      `json:"user_id"`
+	Username     string      `json:"username"`
+	From         *time.Time  `json:"from,omitempty"`
+	To           *time.Time  `json:"to,omitempty"`
+	TotalSeconds int64       `json:"total_seconds"`
+	ByTask       []TimeTotal `json:"by_task"`
+}
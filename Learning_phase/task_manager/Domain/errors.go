@@ -0,0 +1,88 @@
+package domain
+
+import "errors"
+
+// Error kinds. Errors returned by repositories and usecases wrap one of these
+// so the delivery layer can pick a status code with errors.Is.
+var (
+	ErrNotFound             = errors.New("not found")
+	ErrInvalidID            = errors.New("invalid ID")
+	ErrConflict             = errors.New("conflict")
+	ErrValidation           = errors.New("validation failed")
+	ErrUnauthorized         = errors.New("unauthorized")
+	ErrForbidden            = errors.New("forbidden")
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrPreconditionRequired = errors.New("precondition required")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrUnprocessable        = errors.New("unprocessable entity")
+	ErrTooManyRequests      = errors.New("too many requests")
+	ErrInternal             = errors.New("internal error")
+)
+
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+	Details map[string]interface{}
+}
+
+func NewError(kind error, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Message != "":
+		return e.Message
+	case e.Err != nil:
+		return e.Err.Error()
+	default:
+		return e.Kind.Error()
+	}
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// ProblemDetails returns extra members for the problem+json response body.
+func (e *Error) ProblemDetails() map[string]interface{} {
+	return e.Details
+}
+
+func NotFound(resource string) error {
+	return NewError(ErrNotFound, resource+" not found")
+}
+
+func InvalidID(resource string) error {
+	return NewError(ErrInvalidID, "invalid "+resource+" ID")
+}
+
+func Conflict(message string) error {
+	return NewError(ErrConflict, message)
+}
+
+func Validation(message string) error {
+	return NewError(ErrValidation, message)
+}
+
+func Unauthorized(message string) error {
+	return NewError(ErrUnauthorized, message)
+}
+
+func Forbidden(message string) error {
+	return NewError(ErrForbidden, message)
+}
+
+// Internal marks an unexpected failure, such as a database error. Its message
+// is not shown to clients.
+func Internal(err error) error {
+	var domainErr *Error
+	if err == nil || errors.As(err, &domainErr) {
+		return err
+	}
+	return &Error{Kind: ErrInternal, Err: err}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	LabelMatchAny = "any"
+	LabelMatchAll = "all"
+)
+
+// Label is an admin-defined tag. Tasks carry label names, so a rename has to
+// be applied to every task that uses the label.
+type Label struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	Color     string             `json:"color" bson:"color"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type LabelRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color" binding:"required"`
+}
+
+type AttachLabelRequest struct {
+	Label string `json:"label" binding:"required"`
+}
+
+// NormalizeLabelName folds a label name to the form stored on tasks, so
+// lookups and queries are case-insensitive.
+func NormalizeLabelName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// LockoutPolicy controls how failed logins are throttled. Once a key has
+// Threshold consecutive failures it is locked for BaseDelay, doubling with
+// every further failure up to MaxDelay. Failures older than Window are
+// forgotten.
+type LockoutPolicy struct {
+	Threshold   int
+	IPThreshold int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Window      time.Duration
+}
+
+// Delay returns how long to lock a key that has failed the given number of
+// times, or zero if it is still under the threshold.
+func (p LockoutPolicy) Delay(failures, threshold int) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := threshold; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+type LockoutStatus struct {
+	Username       string     `json:"username"`
+	FailedAttempts int        `json:"failed_attempts"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+}
+
+// LoginThrottle counts failed logins for a key that has no user record to
+// keep them on: a client IP or a username that does not exist.
+type LoginThrottle struct {
+	Key         string    `bson:"_id"`
+	Failures    int       `bson:"failures"`
+	LastFailure time.Time `bson:"last_failure"`
+	LockedUntil time.Time `bson:"locked_until"`
+	// ExpiresAt is when the entry can be forgotten: once the failures have
+	// left the window and any lock has ended.
+	ExpiresAt time.Time `bson:"expires_at"`
+}
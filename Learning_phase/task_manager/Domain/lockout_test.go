@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutPolicyDelay(t *testing.T) {
+	policy := LockoutPolicy{BaseDelay: time.Minute, MaxDelay: 15 * time.Minute}
+
+	tests := []struct {
+		name      string
+		policy    LockoutPolicy
+		failures  int
+		threshold int
+		want      time.Duration
+	}{
+		{name: "no failures", policy: policy, failures: 0, threshold: 5, want: 0},
+		{name: "under the threshold", policy: policy, failures: 4, threshold: 5, want: 0},
+		{name: "at the threshold", policy: policy, failures: 5, threshold: 5, want: time.Minute},
+		{name: "doubles per failure", policy: policy, failures: 6, threshold: 5, want: 2 * time.Minute},
+		{name: "doubles again", policy: policy, failures: 8, threshold: 5, want: 8 * time.Minute},
+		{name: "capped", policy: policy, failures: 9, threshold: 5, want: 15 * time.Minute},
+		{name: "stays capped", policy: policy, failures: 1000, threshold: 5, want: 15 * time.Minute},
+		{name: "threshold of one", policy: policy, failures: 1, threshold: 1, want: time.Minute},
+		{name: "disabled threshold", policy: policy, failures: 100, threshold: 0, want: 0},
+		{name: "negative threshold", policy: policy, failures: 100, threshold: -1, want: 0},
+		{
+			name:      "base above max",
+			policy:    LockoutPolicy{BaseDelay: time.Hour, MaxDelay: time.Minute},
+			failures:  5,
+			threshold: 5,
+			want:      time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Delay(tt.failures, tt.threshold); got != tt.want {
+				t.Errorf("Delay(%d, %d) = %v, want %v", tt.failures, tt.threshold, got, tt.want)
+			}
+		})
+	}
+}
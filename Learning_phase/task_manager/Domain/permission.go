@@ -0,0 +1,73 @@
+package domain
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+const (
+	PermTasksReadOwn   = "tasks:read:own"
+	PermTasksReadAny   = "tasks:read:any"
+	PermTasksWriteOwn  = "tasks:write:own"
+	PermTasksWriteAny  = "tasks:write:any"
+	PermTasksDeleteOwn = "tasks:delete:own"
+	PermTasksDeleteAny = "tasks:delete:any"
+	PermTasksTrash     = "tasks:trash"
+	PermCommentsManage = "comments:manage"
+	PermLabelsManage   = "labels:manage"
+	PermTimeLogsManage = "timelogs:manage"
+	PermUsersPromote   = "users:promote"
+	PermUsersUnlock    = "users:unlock"
+	PermRolesManage    = "roles:manage"
+	PermKeysRotate     = "keys:rotate"
+	PermProjectsCreate = "projects:create"
+	PermProjectsManage = "projects:manage"
+	PermAuditRead      = "audit:read"
+)
+
+var AllPermissions = []string{
+	PermTasksReadOwn,
+	PermTasksReadAny,
+	PermTasksWriteOwn,
+	PermTasksWriteAny,
+	PermTasksDeleteOwn,
+	PermTasksDeleteAny,
+	PermTasksTrash,
+	PermCommentsManage,
+	PermLabelsManage,
+	PermTimeLogsManage,
+	PermUsersPromote,
+	PermUsersUnlock,
+	PermRolesManage,
+	PermKeysRotate,
+	PermProjectsCreate,
+	PermProjectsManage,
+	PermAuditRead,
+}
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+type Role struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name" binding:"required"`
+	Permissions []string           `json:"permissions" bson:"permissions" binding:"required"`
+	BuiltIn     bool               `json:"built_in" bson:"-"`
+}
+
+var BuiltInRoles = []Role{
+	{Name: RoleAdmin, Permissions: AllPermissions, BuiltIn: true},
+	{Name: RoleUser, Permissions: []string{PermTasksReadOwn, PermTasksWriteOwn, PermProjectsCreate}, BuiltIn: true},
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+func IsPermission(permission string) bool {
+	for _, p := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
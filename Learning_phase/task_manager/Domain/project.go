@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ProjectRoleViewer = "viewer"
+	ProjectRoleMember = "member"
+	ProjectRoleOwner  = "owner"
+)
+
+var projectRoleRanks = map[string]int{
+	ProjectRoleViewer: 1,
+	ProjectRoleMember: 2,
+	ProjectRoleOwner:  3,
+}
+
+// ProjectRolePermissions are the task permissions a project role grants on
+// the tasks of that project.
+var ProjectRolePermissions = map[string][]string{
+	ProjectRoleViewer: {PermTasksReadAny},
+	ProjectRoleMember: {PermTasksReadAny, PermTasksWriteAny, PermTasksDeleteOwn},
+	ProjectRoleOwner:  {PermTasksReadAny, PermTasksWriteAny, PermTasksDeleteAny},
+}
+
+func IsProjectRole(role string) bool {
+	_, ok := projectRoleRanks[role]
+	return ok
+}
+
+// ProjectRoleAtLeast reports whether role grants at least what min grants.
+func ProjectRoleAtLeast(role, min string) bool {
+	return projectRoleRanks[role] >= projectRoleRanks[min] && IsProjectRole(role)
+}
+
+// ProjectPermissions replaces the task read/write/delete permissions in
+// granted with those of the project role, so a viewer cannot write inside a
+// project even if their global role could.
+func ProjectPermissions(granted []string, role string) []string {
+	permissions := make([]string, 0, len(granted)+len(ProjectRolePermissions[role]))
+	for _, p := range granted {
+		switch p {
+		case PermTasksReadOwn, PermTasksReadAny, PermTasksWriteOwn, PermTasksWriteAny, PermTasksDeleteOwn, PermTasksDeleteAny:
+		default:
+			permissions = append(permissions, p)
+		}
+	}
+	return append(permissions, ProjectRolePermissions[role]...)
+}
+
+type Project struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Key         string             `json:"key" bson:"key"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	Members     []ProjectMember    `json:"members" bson:"members"`
+	CreatedBy   string             `json:"created_by" bson:"created_by"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+func (p Project) Member(userID string) (ProjectMember, bool) {
+	for _, m := range p.Members {
+		if m.UserID == userID {
+			return m, true
+		}
+	}
+	return ProjectMember{}, false
+}
+
+type ProjectMember struct {
+	UserID   string `json:"user_id" bson:"user_id"`
+	Username string `json:"username" bson:"username"`
+	Role     string `json:"role" bson:"role"`
+}
+
+type CreateProjectRequest struct {
+	Key         string `json:"key" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type UpdateProjectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type ProjectMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
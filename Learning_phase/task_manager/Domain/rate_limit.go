@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit allows Requests per Period, refilled continuously, with bursts
+// of up to Requests. A zero RateLimit means unlimited.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+func (l RateLimit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// ParseRateLimit parses "<requests>/<period>", e.g. "100/1m". "off" and ""
+// disable the limit.
+func ParseRateLimit(spec string) (RateLimit, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "off" {
+		return RateLimit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(spec, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("expected <requests>/<period>, got %q", spec)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n < 1 {
+		return RateLimit{}, fmt.Errorf("requests must be a positive number, got %q", requests)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("period must be a positive duration, got %q", period)
+	}
+	return RateLimit{Requests: n, Period: d}, nil
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    RateLimit
+		wantErr bool
+	}{
+		{spec: "100/1m", want: RateLimit{Requests: 100, Period: time.Minute}},
+		{spec: " 5 / 30s ", want: RateLimit{Requests: 5, Period: 30 * time.Second}},
+		{spec: "1/1h30m", want: RateLimit{Requests: 1, Period: 90 * time.Minute}},
+		{spec: "off", want: RateLimit{}},
+		{spec: "", want: RateLimit{}},
+		{spec: "   ", want: RateLimit{}},
+		{spec: "100", wantErr: true},
+		{spec: "100/", wantErr: true},
+		{spec: "/1m", wantErr: true},
+		{spec: "abc/1m", wantErr: true},
+		{spec: "0/1m", wantErr: true},
+		{spec: "-1/1m", wantErr: true},
+		{spec: "5/0s", wantErr: true},
+		{spec: "5/-1m", wantErr: true},
+		{spec: "5/minute", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseRateLimit(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRateLimit(%q) = %+v, want an error", tt.spec, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRateLimit(%q) error = %v", tt.spec, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRateLimit(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+			if got.Enabled() != (tt.want.Requests > 0) {
+				t.Errorf("ParseRateLimit(%q).Enabled() = %v", tt.spec, got.Enabled())
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+var RecurrenceFrequencies = []string{FreqDaily, FreqWeekly, FreqMonthly}
+
+const (
+	EditScopeThis   = "this"
+	EditScopeFuture = "future"
+)
+
+var Weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// maxRecurrencePeriods bounds how far a rule is expanded looking for an
+// occurrence, so a rule that never matches cannot loop forever.
+const maxRecurrencePeriods = 100000
+
+// RecurrenceRule is the subset of an iCalendar RRULE that tasks support:
+// FREQ, INTERVAL, BYDAY (plain weekdays only), UNTIL and COUNT. TimeZone is
+// the IANA name of the DTSTART's TZID; an empty one means UTC.
+type RecurrenceRule struct {
+	Freq      string     `json:"freq" bson:"freq" binding:"required"`
+	Interval  int        `json:"interval" bson:"interval"`
+	ByWeekday []string   `json:"by_weekday,omitempty" bson:"by_weekday,omitempty"`
+	Until     *time.Time `json:"until,omitempty" bson:"until,omitempty"`
+	Count     int        `json:"count,omitempty" bson:"count,omitempty"`
+	TimeZone  string     `json:"tzid,omitempty" bson:"tzid,omitempty"`
+}
+
+// Recurrence is carried by every occurrence of a recurring task. Start is
+// the due date of the first occurrence (the rule's DTSTART) and Occurrence
+// is this task's 1-based position in the series. Template holds the fields
+// copied into the next occurrence, so edits to a single occurrence do not
+// leak into the rest of the series.
+type Recurrence struct {
+	RecurrenceRule `bson:",inline"`
+	Start          time.Time      `json:"dtstart" bson:"dtstart"`
+	SeriesID       string         `json:"series_id" bson:"series_id"`
+	Occurrence     int            `json:"occurrence" bson:"occurrence"`
+	Template       SeriesTemplate `json:"-" bson:"template"`
+	NextCreated    bool           `json:"-" bson:"next_created"`
+}
+
+type SeriesTemplate struct {
+	Title           string `bson:"title"`
+	Description     string `bson:"description"`
+	Priority        string `bson:"priority"`
+	EstimateMinutes int    `bson:"estimate_minutes,omitempty"`
+	AssigneeID      string `bson:"assignee_id"`
+}
+
+func NewSeriesTemplate(task Task) SeriesTemplate {
+	return SeriesTemplate{
+		Title:           task.Title,
+		Description:     task.Description,
+		Priority:        task.Priority,
+		EstimateMinutes: task.EstimateMinutes,
+		AssigneeID:      task.AssigneeID,
+	}
+}
+
+func IsRecurrenceFrequency(freq string) bool {
+	for _, f := range RecurrenceFrequencies {
+		if f == freq {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize upper-cases the rule, defaults the interval to 1 and orders the
+// weekdays Monday first without duplicates.
+func (r RecurrenceRule) Normalize() RecurrenceRule {
+	r.Freq = strings.ToUpper(strings.TrimSpace(r.Freq))
+	r.TimeZone = strings.TrimSpace(r.TimeZone)
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	seen := map[string]bool{}
+	var days []string
+	for _, day := range r.ByWeekday {
+		day = strings.ToUpper(strings.TrimSpace(day))
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.SliceStable(days, func(i, j int) bool {
+		return weekdayIndex(days[i]) < weekdayIndex(days[j])
+	})
+	r.ByWeekday = days
+	if r.Until != nil {
+		until := r.Until.UTC()
+		r.Until = &until
+	}
+	return r
+}
+
+// Location returns the rule's time zone, or UTC when it has none or the
+// name is unknown.
+func (r RecurrenceRule) Location() *time.Location {
+	if r.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Next returns the first occurrence of the rule that follows the one at
+// position occurrence and falls strictly after after, with its 1-based
+// position in the series, or false once the series has ended.
+// As in RFC 5545, start is always the first occurrence and counts towards
+// COUNT; later occurrences keep its local time of day in the rule's time
+// zone, across daylight saving changes. Occurrences are returned in UTC.
+func (r RecurrenceRule) Next(start time.Time, occurrence int, after time.Time) (time.Time, int, bool) {
+	var next time.Time
+	index := 0
+	r.expand(start.In(r.Location()), func(t time.Time) bool {
+		index++
+		if (r.Count > 0 && index > r.Count) || (r.Until != nil && t.After(*r.Until)) {
+			return false
+		}
+		if index > occurrence && t.After(after) {
+			next = t.UTC()
+			return false
+		}
+		return true
+	})
+	if next.IsZero() {
+		return time.Time{}, 0, false
+	}
+	return next, index, true
+}
+
+// expand yields the occurrences of the rule in order until yield returns
+// false or maxRecurrencePeriods periods have been examined. Days are counted
+// in start's location.
+func (r RecurrenceRule) expand(start time.Time, yield func(time.Time) bool) {
+	if !yield(start) {
+		return
+	}
+	interval := max(r.Interval, 1)
+
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		var days []time.Time
+		switch r.Freq {
+		case FreqDaily:
+			day := midnight(start).AddDate(0, 0, period*interval)
+			if len(r.ByWeekday) == 0 || r.onWeekday(day) {
+				days = append(days, day)
+			}
+		case FreqWeekly:
+			monday := midnight(start).AddDate(0, 0, -((int(start.Weekday())+6)%7)+period*interval*7)
+			weekdays := r.ByWeekday
+			if len(weekdays) == 0 {
+				weekdays = []string{weekdayCode(start.Weekday())}
+			}
+			for _, code := range weekdays {
+				days = append(days, monday.AddDate(0, 0, weekdayIndex(code)))
+			}
+		case FreqMonthly:
+			first := time.Date(start.Year(), start.Month()+time.Month(period*interval), 1, 0, 0, 0, 0, start.Location())
+			if len(r.ByWeekday) == 0 {
+				// Months without the start's day of month are skipped.
+				if day := first.AddDate(0, 0, start.Day()-1); day.Month() == first.Month() {
+					days = append(days, day)
+				}
+				break
+			}
+			for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+				if r.onWeekday(day) {
+					days = append(days, day)
+				}
+			}
+		default:
+			return
+		}
+
+		for _, day := range days {
+			t := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+			if t.After(start) && !yield(t) {
+				return
+			}
+		}
+	}
+}
+
+func (r RecurrenceRule) onWeekday(day time.Time) bool {
+	for _, code := range r.ByWeekday {
+		if Weekdays[code] == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// weekdayIndex orders weekday codes from Monday (0) to Sunday (6).
+func weekdayIndex(code string) int {
+	if day, ok := Weekdays[code]; ok {
+		return (int(day) + 6) % 7
+	}
+	return 7
+}
+
+func weekdayCode(day time.Weekday) string {
+	for code, d := range Weekdays {
+		if d == day {
+			return code
+		}
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func date(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestRecurrenceRuleNext(t *testing.T) {
+	until := date("2027-01-03T09:00:00Z")
+
+	tests := []struct {
+		name       string
+		rule       RecurrenceRule
+		start      string
+		occurrence int
+		after      string
+		want       string
+		wantIndex  int
+		wantEnded  bool
+	}{
+		{
+			name:       "daily",
+			rule:       RecurrenceRule{Freq: FreqDaily},
+			start:      "2027-01-01T09:00:00Z",
+			occurrence: 1,
+			after:      "2027-01-01T09:00:00Z",
+			want:       "2027-01-02T09:00:00Z",
+			wantIndex:  2,
+		},
+		{
+			name:       "count includes the start",
+			rule:       RecurrenceRule{Freq: FreqDaily, Count: 3},
+			start:      "2027-01-01T09:00:00Z",
+			occurrence: 2,
+			after:      "2027-01-02T09:00:00Z",
+			want:       "2027-01-03T09:00:00Z",
+			wantIndex:  3,
+		},
+		{
+			name:       "count exhausted",
+			rule:       RecurrenceRule{Freq: FreqDaily, Count: 3},
+			start:      "2027-01-01T09:00:00Z",
+			occurrence: 3,
+			after:      "2027-01-03T09:00:00Z",
+			wantEnded:  true,
+		},
+		{
+			name:       "until is inclusive",
+			rule:       RecurrenceRule{Freq: FreqDaily, Until: &until},
+			start:      "2027-01-01T09:00:00Z",
+			occurrence: 2,
+			after:      "2027-01-02T09:00:00Z",
+			want:       "2027-01-03T09:00:00Z",
+			wantIndex:  3,
+		},
+		{
+			name:       "until passed",
+			rule:       RecurrenceRule{Freq: FreqDaily, Until: &until},
+			start:      "2027-01-01T09:00:00Z",
+			occurrence: 3,
+			after:      "2027-01-03T09:00:00Z",
+			wantEnded:  true,
+		},
+		{
+			name:       "weekly by day from a start on another weekday",
+			rule:       RecurrenceRule{Freq: FreqWeekly, ByWeekday: []string{"MO", "FR"}},
+			start:      "2027-01-06T09:00:00Z", // a Wednesday
+			occurrence: 1,
+			after:      "2027-01-06T09:00:00Z",
+			want:       "2027-01-08T09:00:00Z",
+			wantIndex:  2,
+		},
+		{
+			name:       "weekly by day continues into the next week",
+			rule:       RecurrenceRule{Freq: FreqWeekly, ByWeekday: []string{"MO", "FR"}},
+			start:      "2027-01-06T09:00:00Z",
+			occurrence: 2,
+			after:      "2027-01-08T09:00:00Z",
+			want:       "2027-01-11T09:00:00Z",
+			wantIndex:  3,
+		},
+		{
+			name:       "daily interval",
+			rule:       RecurrenceRule{Freq: FreqDaily, Interval: 3},
+			start:      "2027-01-04T09:00:00Z",
+			occurrence: 1,
+			after:      "2027-01-04T09:00:00Z",
+			want:       "2027-01-07T09:00:00Z",
+			wantIndex:  2,
+		},
+		{
+			name:       "weekly interval",
+			rule:       RecurrenceRule{Freq: FreqWeekly, Interval: 2},
+			start:      "2027-01-04T09:00:00Z",
+			occurrence: 1,
+			after:      "2027-01-04T09:00:00Z",
+			want:       "2027-01-18T09:00:00Z",
+			wantIndex:  2,
+		},
+		{
+			name:       "monthly interval",
+			rule:       RecurrenceRule{Freq: FreqMonthly, Interval: 2},
+			start:      "2027-01-15T09:00:00Z",
+			occurrence: 1,
+			after:      "2027-01-15T09:00:00Z",
+			want:       "2027-03-15T09:00:00Z",
+			wantIndex:  2,
+		},
+		{
+			name:       "monthly skips february",
+			rule:       RecurrenceRule{Freq: FreqMonthly},
+			start:      "2027-01-31T09:00:00Z",
+			occurrence: 1,
+			after:      "2027-01-31T09:00:00Z",
+			want:       "2027-03-31T09:00:00Z",
+			wantIndex:  2,
+		},
+		{
+			name:       "monthly skips april",
+			rule:       RecurrenceRule{Freq: FreqMonthly},
+			start:      "2027-01-31T09:00:00Z",
+			occurrence: 2,
+			after:      "2027-03-31T09:00:00Z",
+			want:       "2027-05-31T09:00:00Z",
+			wantIndex:  3,
+		},
+		{
+			name:       "missed occurrences are skipped",
+			rule:       RecurrenceRule{Freq: FreqDaily},
+			start:      "2027-01-01T09:00:00Z",
+			occurrence: 1,
+			after:      "2027-01-05T10:00:00Z",
+			want:       "2027-01-06T09:00:00Z",
+			wantIndex:  6,
+		},
+		{
+			name:       "position wins over an earlier after",
+			rule:       RecurrenceRule{Freq: FreqDaily},
+			start:      "2027-01-01T09:00:00Z",
+			occurrence: 3,
+			after:      "2026-12-01T00:00:00Z",
+			want:       "2027-01-04T09:00:00Z",
+			wantIndex:  4,
+		},
+		{
+			name:       "local time is kept across daylight saving",
+			rule:       RecurrenceRule{Freq: FreqWeekly, TimeZone: "Europe/Paris"},
+			start:      "2027-03-22T08:00:00Z", // 09:00 CET
+			occurrence: 1,
+			after:      "2027-03-22T08:00:00Z",
+			want:       "2027-03-29T07:00:00Z", // 09:00 CEST
+			wantIndex:  2,
+		},
+		{
+			name:       "weekdays are taken in the time zone",
+			rule:       RecurrenceRule{Freq: FreqWeekly, ByWeekday: []string{"MO"}, TimeZone: "America/New_York"},
+			start:      "2027-01-05T02:00:00Z", // Monday 21:00 in New York
+			occurrence: 1,
+			after:      "2027-01-05T02:00:00Z",
+			want:       "2027-01-12T02:00:00Z",
+			wantIndex:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := tt.rule.Normalize()
+			got, index, ok := rule.Next(date(tt.start), tt.occurrence, date(tt.after))
+			if tt.wantEnded {
+				if ok {
+					t.Fatalf("Next() = %v (#%d), want the series to have ended", got, index)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("Next() ended the series, want %s", tt.want)
+			}
+			if !got.Equal(date(tt.want)) || index != tt.wantIndex {
+				t.Errorf("Next() = %v (#%d), want %s (#%d)", got, index, tt.want, tt.wantIndex)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ReminderDueSoon = "due_soon"
+	ReminderOverdue = "overdue"
+)
+
+// ReminderPolicy configures the reminder worker. A task that is not done
+// gets a due_soon reminder for the smallest window its due date falls into,
+// and one overdue reminder once the due date has passed. Tasks overdue for
+// longer than OverdueLookback are no longer looked at.
+type ReminderPolicy struct {
+	Windows         []time.Duration
+	OverdueLookback time.Duration
+}
+
+// Reminder is a notification about a task's due date. TaskID, Kind, Window
+// and DueDate identify it, so each is sent at most once and moving the due
+// date arms the reminders again.
+type Reminder struct {
+	ID         primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	TaskID     string             `json:"task_id" bson:"task_id"`
+	Kind       string             `json:"kind" bson:"kind"`
+	Window     string             `json:"window,omitempty" bson:"window"`
+	DueDate    time.Time          `json:"due_date" bson:"due_date"`
+	Title      string             `json:"title" bson:"title"`
+	ProjectID  string             `json:"project_id,omitempty" bson:"project_id,omitempty"`
+	AssigneeID string             `json:"assignee_id" bson:"assignee_id"`
+	Assignee   string             `json:"assignee,omitempty" bson:"assignee,omitempty"`
+	// Recipient is the username the reminder goes to: the assignee, or the
+	// task's creator when it has no assignee that still exists.
+	Recipient string    `json:"recipient,omitempty" bson:"recipient,omitempty"`
+	SentAt    time.Time `json:"sent_at" bson:"sent_at"`
+}
+
+// FormatWindow renders a reminder window without trailing zero units, so
+// 24h reads "24h" rather than "24h0m0s".
+func FormatWindow(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
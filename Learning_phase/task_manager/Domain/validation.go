@@ -0,0 +1,42 @@
+package domain
+
+import "strings"
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrors collects every invalid field of a request so they can be
+// reported together instead of one at a time.
+type FieldErrors []FieldError
+
+func (f *FieldErrors) Add(field, message string) {
+	*f = append(*f, FieldError{Field: field, Message: message})
+}
+
+// Err returns nil when no field failed, otherwise a validation error that
+// carries the field list in its problem details.
+func (f FieldErrors) Err() error {
+	if len(f) == 0 {
+		return nil
+	}
+	messages := make([]string, len(f))
+	for i, fe := range f {
+		messages[i] = fe.Field + ": " + fe.Message
+	}
+	return &Error{
+		Kind:    ErrValidation,
+		Message: strings.Join(messages, "; "),
+		Details: map[string]interface{}{"errors": f},
+	}
+}
+
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	Denylist      map[string]bool
+}
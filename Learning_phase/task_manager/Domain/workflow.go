@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusBlocked    = "blocked"
+	StatusDone       = "done"
+	StatusArchived   = "archived"
+)
+
+var TaskStatuses = []string{StatusTodo, StatusInProgress, StatusBlocked, StatusDone, StatusArchived}
+
+func IsTaskStatus(status string) bool {
+	for _, s := range TaskStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+type StatusChange struct {
+	Status    string    `json:"status" bson:"status"`
+	EnteredAt time.Time `json:"entered_at" bson:"entered_at"`
+	By        string    `json:"by,omitempty" bson:"by,omitempty"`
+}
+
+type TransitionRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// Workflow maps a status to the statuses a task may move to from it.
+type Workflow map[string][]string
+
+var DefaultWorkflow = Workflow{
+	StatusTodo:       {StatusInProgress, StatusBlocked, StatusDone, StatusArchived},
+	StatusInProgress: {StatusTodo, StatusBlocked, StatusDone, StatusArchived},
+	StatusBlocked:    {StatusTodo, StatusInProgress, StatusArchived},
+	StatusDone:       {StatusInProgress, StatusArchived},
+	StatusArchived:   {StatusTodo},
+}
+
+func (w Workflow) CanTransition(from, to string) bool {
+	for _, next := range w[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseWorkflow reads a transition graph written as
+// "todo:in_progress,done;in_progress:done".
+func ParseWorkflow(spec string) (Workflow, error) {
+	workflow := Workflow{}
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		from, targets, ok := strings.Cut(rule, ":")
+		from = strings.TrimSpace(from)
+		if !ok || !IsTaskStatus(from) {
+			return nil, fmt.Errorf("invalid workflow rule %q", rule)
+		}
+		for _, to := range strings.Split(targets, ",") {
+			to = strings.TrimSpace(to)
+			if !IsTaskStatus(to) || to == from {
+				return nil, fmt.Errorf("invalid transition %q in workflow rule %q", to, rule)
+			}
+			workflow[from] = append(workflow[from], to)
+		}
+	}
+	if len(workflow) == 0 {
+		return nil, fmt.Errorf("workflow %q defines no transitions", spec)
+	}
+	return workflow, nil
+}
+
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot move task from %q to %q", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrConflict
+}
+
+func (e *TransitionError) ProblemDetails() map[string]interface{} {
+	return map[string]interface{}{"code": "invalid_transition", "from": e.From, "to": e.To}
+}
+
+// BlockedError reports that a task cannot be completed because the listed
+// blockers are still open.
+type BlockedError struct {
+	Blockers []string
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("task is blocked by %d open task(s)", len(e.Blockers))
+}
+
+func (e *BlockedError) Unwrap() error {
+	return ErrConflict
+}
+
+func (e *BlockedError) ProblemDetails() map[string]interface{} {
+	return map[string]interface{}{"code": "blocked", "blocked_by": e.Blockers}
+}
@@ -1,39 +1,47 @@
 package infrastructure
 
 import (
-	"net/http"
+	"context"
+	"errors"
 	"strings"
+	"task_manager/Domain"
 
 	"github.com/gin-gonic/gin"
 )
 
+// ProjectMembership resolves the role a user holds in a project.
+type ProjectMembership interface {
+	MemberRole(ctx context.Context, projectID, userID string) (string, error)
+}
+
 type AuthMiddleware struct {
 	jwtService *JWTService
+	projects   ProjectMembership
 }
 
-func NewAuthMiddleware(jwtService *JWTService) *AuthMiddleware {
-	return &AuthMiddleware{jwtService: jwtService}
+func NewAuthMiddleware(jwtService *JWTService, projects ProjectMembership) *AuthMiddleware {
+	return &AuthMiddleware{jwtService: jwtService, projects: projects}
 }
 
 func (am *AuthMiddleware) AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			c.Error(domain.Unauthorized("Authorization header required"))
 			c.Abort()
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token format"})
+			c.Error(domain.Unauthorized("Invalid token format"))
 			c.Abort()
 			return
 		}
 
 		claims, err := am.jwtService.ValidateToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Error(domain.Unauthorized("Invalid token"))
 			c.Abort()
 			return
 		}
@@ -41,18 +49,75 @@ func (am *AuthMiddleware) AuthRequired() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
+		c.Set("permissions", claims.Permissions)
 		c.Next()
 	}
 }
 
-func (am *AuthMiddleware) AdminOnly() gin.HandlerFunc {
+// RequirePermission lets the request through when the caller holds at
+// least one of the given permissions.
+func (am *AuthMiddleware) RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted := c.GetStringSlice("permissions")
+		for _, required := range permissions {
+			for _, p := range granted {
+				if p == required {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		c.Error(domain.Forbidden("Missing permission: " + strings.Join(permissions, " or ")))
+		c.Abort()
+	}
+}
+
+// RequireProjectRole checks that the caller belongs to the project named by
+// the :pid route parameter with at least the given role. Non-members get a
+// 404 so they cannot probe for projects; holders of projects:manage act as
+// owners of every project. The request's task permissions are replaced by
+// those of the project role.
+func (am *AuthMiddleware) RequireProjectRole(min string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists || role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		projectID := c.Param("pid")
+		granted := c.GetStringSlice("permissions")
+
+		role, err := am.projects.MemberRole(c.Request.Context(), projectID, c.GetString("user_id"))
+		if errors.Is(err, domain.ErrInvalidID) {
+			err = domain.NotFound("project")
+		}
+		if err != nil {
+			c.Error(err)
 			c.Abort()
 			return
 		}
+		if hasPermission(granted, domain.PermProjectsManage) {
+			role = domain.ProjectRoleOwner
+		}
+		if role == "" {
+			c.Error(domain.NotFound("project"))
+			c.Abort()
+			return
+		}
+		if !domain.ProjectRoleAtLeast(role, min) {
+			c.Error(domain.Forbidden("Requires project role " + min))
+			c.Abort()
+			return
+		}
+
+		c.Set("project_id", projectID)
+		c.Set("project_role", role)
+		c.Set("permissions", domain.ProjectPermissions(granted, role))
 		c.Next()
 	}
 }
+
+func hasPermission(granted []string, permission string) bool {
+	for _, p := range granted {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
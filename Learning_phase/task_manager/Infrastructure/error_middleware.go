@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"task_manager/Domain"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type problemKind struct {
+	kind   error
+	status int
+	code   string
+}
+
+var problemKinds = []problemKind{
+	{domain.ErrNotFound, http.StatusNotFound, "not_found"},
+	{domain.ErrInvalidID, http.StatusBadRequest, "invalid_id"},
+	{domain.ErrConflict, http.StatusConflict, "conflict"},
+	{domain.ErrValidation, http.StatusBadRequest, "validation_failed"},
+	{domain.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+	{domain.ErrForbidden, http.StatusForbidden, "forbidden"},
+	{domain.ErrPreconditionFailed, http.StatusPreconditionFailed, "precondition_failed"},
+	{domain.ErrPreconditionRequired, http.StatusPreconditionRequired, "precondition_required"},
+	{domain.ErrUnsupportedMediaType, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	{domain.ErrUnprocessable, http.StatusUnprocessableEntity, "unprocessable_entity"},
+	{domain.ErrTooManyRequests, http.StatusTooManyRequests, "too_many_requests"},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout"},
+	{context.Canceled, statusClientClosedRequest, "client_closed_request"},
+}
+
+// statusClientClosedRequest is the non-standard status nginx uses when the
+// client went away before a response was written. It only shows up in logs.
+const statusClientClosedRequest = 499
+
+// ErrorHandler renders the last error a handler attached with c.Error as an
+// RFC 7807 application/problem+json response. Errors that wrap none of the
+// domain error kinds are logged and reported as a 500 without their message.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+
+		status, code, detail := http.StatusInternalServerError, "internal_error", "an unexpected error occurred"
+		for _, k := range problemKinds {
+			if errors.Is(err, k.kind) {
+				status, code, detail = k.status, k.code, err.Error()
+				break
+			}
+		}
+		if status == http.StatusInternalServerError {
+			log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		}
+
+		title := http.StatusText(status)
+		if status == statusClientClosedRequest {
+			title = "Client Closed Request"
+		}
+		problem := gin.H{
+			"type":     "about:blank",
+			"title":    title,
+			"status":   status,
+			"detail":   detail,
+			"instance": c.Request.URL.Path,
+			"code":     code,
+		}
+		var details interface{ ProblemDetails() map[string]interface{} }
+		if status != http.StatusInternalServerError && errors.As(err, &details) {
+			for key, value := range details.ProblemDetails() {
+				problem[key] = value
+			}
+		}
+
+		if retryAfter, ok := problem["retry_after"].(int); ok {
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+		}
+		c.Header("Content-Type", "application/problem+json")
+		c.JSON(status, problem)
+	}
+}
+
+// RequestTimeout bounds the context every handler passes down to the
+// usecases and repositories. It is cancelled early if the client disconnects.
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
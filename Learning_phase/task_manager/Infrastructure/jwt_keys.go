@@ -0,0 +1,467 @@
+package infrastructure
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const DefaultKeyID = "default"
+
+// ErrKeySetNotSaved is returned when a runtime key change could not be
+// written to the keyset file. The change is not applied.
+var ErrKeySetNotSaved = errors.New("keyset: saving failed")
+
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	secret     []byte
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+func (k *SigningKey) signingKey() interface{} {
+	if k.Algorithm == "HS256" {
+		return k.secret
+	}
+	return k.privateKey
+}
+
+func (k *SigningKey) verificationKey() interface{} {
+	if k.Algorithm == "HS256" {
+		return k.secret
+	}
+	return k.publicKey
+}
+
+type KeySet struct {
+	mu         sync.RWMutex
+	keys       map[string]*SigningKey
+	order      []string
+	signingKID string
+
+	// path and entries are set for keysets loaded from a file, so that
+	// runtime rotations can be written back to it.
+	path    string
+	entries []keySetEntry
+}
+
+type keySetFile struct {
+	SigningKID string        `json:"signing_kid"`
+	Keys       []keySetEntry `json:"keys"`
+}
+
+type keySetEntry struct {
+	KID            string `json:"kid"`
+	Alg            string `json:"alg"`
+	Secret         string `json:"secret,omitempty"`
+	PrivateKeyFile string `json:"private_key_file,omitempty"`
+	PublicKeyFile  string `json:"public_key_file,omitempty"`
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]*SigningKey)}
+}
+
+func NewHMACKeySet(secret string) *KeySet {
+	ks := NewKeySet()
+	ks.add(&SigningKey{ID: DefaultKeyID, Algorithm: "HS256", secret: []byte(secret)})
+	ks.signingKID = DefaultKeyID
+	return ks
+}
+
+// LoadKeySet reads a JSON keyset file. PEM paths are resolved relative to
+// the file. Keys without a private key or secret can only verify tokens.
+// Keys generated or selected at runtime are written back to the file.
+func LoadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading keyset: %w", err)
+	}
+
+	var file keySetFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing keyset: %w", err)
+	}
+
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(filepath.Dir(path), p)
+	}
+
+	ks := NewKeySet()
+	for _, entry := range file.Keys {
+		if entry.KID == "" {
+			return nil, errors.New("keyset: every key needs a kid")
+		}
+		if _, exists := ks.keys[entry.KID]; exists {
+			return nil, fmt.Errorf("keyset: duplicate kid %q", entry.KID)
+		}
+
+		key := &SigningKey{ID: entry.KID, Algorithm: entry.Alg}
+		switch entry.Alg {
+		case "HS256":
+			if len(entry.Secret) < 32 {
+				return nil, fmt.Errorf("keyset: key %q needs a secret of at least 32 characters", entry.KID)
+			}
+			key.secret = []byte(entry.Secret)
+		case "RS256", "ES256":
+			if err := loadPEMKeys(key, resolve(entry.PrivateKeyFile), resolve(entry.PublicKeyFile)); err != nil {
+				return nil, fmt.Errorf("keyset: key %q: %w", entry.KID, err)
+			}
+		default:
+			return nil, fmt.Errorf("keyset: key %q has unsupported alg %q", entry.KID, entry.Alg)
+		}
+		ks.add(key)
+	}
+
+	if file.SigningKID != "" {
+		if err := ks.SetSigningKey(file.SigningKID); err != nil {
+			return nil, err
+		}
+	}
+	ks.path = path
+	ks.entries = file.Keys
+	return ks, nil
+}
+
+// save writes the file-backed keys and the signing kid back to the keyset
+// file. A signing key that did not come from the file (the JWT_SECRET key)
+// is recorded as an empty signing_kid. The caller must hold ks.mu.
+func (ks *KeySet) save(entries []keySetEntry, signingKID string) error {
+	file := keySetFile{Keys: entries}
+	for _, entry := range entries {
+		if entry.KID == signingKID {
+			file.SigningKID = signingKID
+		}
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(ks.path, append(data, '\n'), 0o600)
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func loadPEMKeys(key *SigningKey, privatePath, publicPath string) error {
+	if privatePath == "" && publicPath == "" {
+		return errors.New("private_key_file or public_key_file is required")
+	}
+
+	if privatePath != "" {
+		block, err := readPEM(privatePath)
+		if err != nil {
+			return err
+		}
+		var parsed interface{}
+		switch {
+		case key.Algorithm == "RS256" && block.Type == "RSA PRIVATE KEY":
+			parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case key.Algorithm == "ES256" && block.Type == "EC PRIVATE KEY":
+			parsed, err = x509.ParseECPrivateKey(block.Bytes)
+		default:
+			parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		}
+		if err != nil {
+			return fmt.Errorf("parsing private key: %w", err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return errors.New("unsupported private key type")
+		}
+		key.privateKey = signer
+		key.publicKey = signer.Public()
+	} else {
+		block, err := readPEM(publicPath)
+		if err != nil {
+			return err
+		}
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("parsing public key: %w", err)
+		}
+		key.publicKey = parsed
+	}
+
+	switch pub := key.publicKey.(type) {
+	case *rsa.PublicKey:
+		if key.Algorithm != "RS256" {
+			return errors.New("RSA key used with non-RSA algorithm")
+		}
+	case *ecdsa.PublicKey:
+		if key.Algorithm != "ES256" || pub.Curve != elliptic.P256() {
+			return errors.New("ES256 requires a P-256 key")
+		}
+	default:
+		return errors.New("unsupported public key type")
+	}
+	return nil
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
+
+func (ks *KeySet) add(key *SigningKey) {
+	if _, exists := ks.keys[key.ID]; !exists {
+		ks.order = append(ks.order, key.ID)
+	}
+	ks.keys[key.ID] = key
+}
+
+func (ks *KeySet) AddHMACKey(kid, secret string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if _, exists := ks.keys[kid]; exists {
+		return fmt.Errorf("keyset: duplicate kid %q", kid)
+	}
+	ks.add(&SigningKey{ID: kid, Algorithm: "HS256", secret: []byte(secret)})
+	if ks.signingKID == "" {
+		ks.signingKID = kid
+	}
+	return nil
+}
+
+func (ks *KeySet) SetSigningKey(kid string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return fmt.Errorf("keyset: unknown kid %q", kid)
+	}
+	if key.Algorithm != "HS256" && key.privateKey == nil {
+		return fmt.Errorf("keyset: key %q has no private key and cannot sign", kid)
+	}
+	ks.signingKID = kid
+	return nil
+}
+
+// Activate makes kid the signing key and, for file-backed keysets, records
+// the choice in the keyset file so it survives a restart.
+func (ks *KeySet) Activate(kid string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return fmt.Errorf("keyset: unknown kid %q", kid)
+	}
+	if key.Algorithm != "HS256" && key.privateKey == nil {
+		return fmt.Errorf("keyset: key %q has no private key and cannot sign", kid)
+	}
+	if ks.path != "" {
+		if err := ks.save(ks.entries, kid); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrKeySetNotSaved, ks.path, err)
+		}
+	}
+	ks.signingKID = kid
+	return nil
+}
+
+// Rotate generates a fresh key with the given algorithm and makes it the
+// signing key. Previous keys stay in the set so outstanding tokens remain
+// valid until they expire. The key is written to the keyset file before it
+// is used, so keysets without a file cannot rotate: tokens signed by a key
+// that only lived in memory would stop validating after a restart.
+func (ks *KeySet) Rotate(alg string) (string, error) {
+	ks.mu.RLock()
+	path := ks.path
+	ks.mu.RUnlock()
+	if path == "" {
+		return "", errors.New("keyset: generating keys requires JWT_KEYS_FILE")
+	}
+
+	if alg == "" {
+		if current, err := ks.SigningKey(); err == nil {
+			alg = current.Algorithm
+		} else {
+			alg = "HS256"
+		}
+	}
+
+	kidBytes := make([]byte, 8)
+	if _, err := rand.Read(kidBytes); err != nil {
+		return "", err
+	}
+	key := &SigningKey{ID: base64.RawURLEncoding.EncodeToString(kidBytes), Algorithm: alg}
+	entry := keySetEntry{KID: key.ID, Alg: alg}
+
+	switch alg {
+	case "HS256":
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return "", err
+		}
+		entry.Secret = base64.RawURLEncoding.EncodeToString(secret)
+		key.secret = []byte(entry.Secret)
+	case "RS256", "ES256":
+		var signer crypto.Signer
+		var err error
+		if alg == "RS256" {
+			signer, err = rsa.GenerateKey(rand.Reader, 2048)
+		} else {
+			signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		}
+		if err != nil {
+			return "", err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(signer)
+		if err != nil {
+			return "", err
+		}
+		entry.PrivateKeyFile = key.ID + ".pem"
+		pemPath := filepath.Join(filepath.Dir(path), entry.PrivateKeyFile)
+		if err := writeFileAtomic(pemPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+			return "", fmt.Errorf("%w: %s: %v", ErrKeySetNotSaved, pemPath, err)
+		}
+		key.privateKey, key.publicKey = signer, signer.Public()
+	default:
+		return "", fmt.Errorf("keyset: unsupported alg %q", alg)
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	entries := append(append([]keySetEntry(nil), ks.entries...), entry)
+	if err := ks.save(entries, key.ID); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrKeySetNotSaved, ks.path, err)
+	}
+	ks.entries = entries
+	ks.add(key)
+	ks.signingKID = key.ID
+	return key.ID, nil
+}
+
+func (ks *KeySet) SigningKey() (*SigningKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[ks.signingKID]
+	if !ok {
+		return nil, errors.New("keyset: no signing key configured")
+	}
+	return key, nil
+}
+
+func (ks *KeySet) Lookup(kid string) (*SigningKey, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	if kid == "" {
+		kid = DefaultKeyID
+	}
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+func (ks *KeySet) Algorithms() []string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var algs []string
+	for _, key := range ks.keys {
+		if !seen[key.Algorithm] {
+			seen[key.Algorithm] = true
+			algs = append(algs, key.Algorithm)
+		}
+	}
+	return algs
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func (ks *KeySet) JWKS() []JWK {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	jwks := []JWK{}
+	for _, kid := range ks.order {
+		key := ks.keys[kid]
+		switch pub := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, JWK{
+				Kty: "RSA", Kid: kid, Alg: key.Algorithm, Use: "sig",
+				N: base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			ecdhKey, err := pub.ECDH()
+			if err != nil {
+				continue
+			}
+			point := ecdhKey.Bytes()[1:]
+			jwks = append(jwks, JWK{
+				Kty: "EC", Kid: kid, Alg: key.Algorithm, Use: "sig", Crv: "P-256",
+				X: base64.RawURLEncoding.EncodeToString(point[:len(point)/2]),
+				Y: base64.RawURLEncoding.EncodeToString(point[len(point)/2:]),
+			})
+		}
+	}
+	return jwks
+}
@@ -0,0 +1,68 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeySetRotatePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyset.json")
+	initial := `{"signing_kid": "old", "keys": [{"kid": "old", "alg": "HS256", "secret": "0123456789abcdef0123456789abcdef"}]}`
+	if err := os.WriteFile(path, []byte(initial), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := LoadKeySet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kids []string
+	for _, alg := range []string{"HS256", "ES256", "RS256"} {
+		kid, err := keys.Rotate(alg)
+		if err != nil {
+			t.Fatalf("Rotate(%q) error = %v", alg, err)
+		}
+		kids = append(kids, kid)
+	}
+
+	reloaded, err := LoadKeySet(path)
+	if err != nil {
+		t.Fatalf("reloading the keyset: %v", err)
+	}
+	for _, kid := range append([]string{"old"}, kids...) {
+		want, _ := keys.Lookup(kid)
+		got, ok := reloaded.Lookup(kid)
+		if !ok {
+			t.Fatalf("key %q was not persisted", kid)
+		}
+		if got.Algorithm != want.Algorithm {
+			t.Errorf("key %q algorithm = %s, want %s", kid, got.Algorithm, want.Algorithm)
+		}
+	}
+	signing, err := reloaded.SigningKey()
+	if err != nil || signing.ID != kids[len(kids)-1] {
+		t.Errorf("reloaded signing key = %v, %v; want %q", signing, err, kids[len(kids)-1])
+	}
+
+	if err := keys.Activate("old"); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err = LoadKeySet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signing, _ := reloaded.SigningKey(); signing == nil || signing.ID != "old" {
+		t.Errorf("reloaded signing key = %v, want %q", signing, "old")
+	}
+}
+
+func TestKeySetRotateWithoutFile(t *testing.T) {
+	keys := NewHMACKeySet("0123456789abcdef0123456789abcdef")
+	if _, err := keys.Rotate("HS256"); err == nil {
+		t.Fatal("Rotate() without a keyset file succeeded, want an error")
+	}
+	if signing, _ := keys.SigningKey(); signing.ID != DefaultKeyID {
+		t.Errorf("signing key = %q, want %q", signing.ID, DefaultKeyID)
+	}
+}
@@ -1,50 +1,102 @@
 package infrastructure
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("your-secret-key-change-in-production")
-
 type Claims struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID      string   `json:"user_id"`
+	Username    string   `json:"username"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
 	jwt.RegisteredClaims
 }
 
-type JWTService struct{}
+type JWTService struct {
+	keys            *KeySet
+	tokenTTL        time.Duration
+	refreshTokenTTL time.Duration
+}
+
+func NewJWTService(keys *KeySet, tokenTTL, refreshTokenTTL time.Duration) *JWTService {
+	return &JWTService{keys: keys, tokenTTL: tokenTTL, refreshTokenTTL: refreshTokenTTL}
+}
+
+func (js *JWTService) Keys() *KeySet {
+	return js.keys
+}
+
+func (js *JWTService) TokenTTL() time.Duration {
+	return js.tokenTTL
+}
 
-func NewJWTService() *JWTService {
-	return &JWTService{}
+func (js *JWTService) RefreshTokenTTL() time.Duration {
+	return js.refreshTokenTTL
 }
 
-func (js *JWTService) GenerateToken(userID, username, role string) (string, error) {
+func (js *JWTService) GenerateToken(userID, username, role string, permissions []string) (string, error) {
 	claims := Claims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:      userID,
+		Username:    username,
+		Role:        role,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(js.tokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	key, err := js.keys.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signingKey())
 }
 
 func (js *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		key, ok := js.keys.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("unexpected signing algorithm %q for key %q", token.Method.Alg(), key.ID)
+		}
+		return key.verificationKey(), nil
+	}, jwt.WithValidMethods(js.keys.Algorithms()), jwt.WithExpirationRequired())
 
-	if err != nil || !token.Valid {
+	if err != nil {
 		return nil, err
 	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
 
 	return claims, nil
 }
+
+func (js *JWTService) GenerateRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func (js *JWTService) HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,191 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"task_manager/Domain"
+	"time"
+)
+
+// Notifier delivers due-date reminders.
+type Notifier interface {
+	Notify(ctx context.Context, reminder domain.Reminder) error
+}
+
+// ErrNoRecipient is returned for a reminder that has nobody to go to.
+// Retrying it cannot succeed.
+var ErrNoRecipient = errors.New("no recipient for the reminder")
+
+type logNotifier struct{}
+
+// NewLogNotifier writes reminders to the server log.
+func NewLogNotifier() Notifier {
+	return logNotifier{}
+}
+
+func (logNotifier) Notify(ctx context.Context, reminder domain.Reminder) error {
+	log.Printf("reminder: %s", reminderSubject(reminder))
+	return nil
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier POSTs each reminder as JSON to url. Any status other
+// than 2xx counts as a failed delivery.
+func NewWebhookNotifier(url string, timeout time.Duration) Notifier {
+	return &webhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, reminder domain.Reminder) error {
+	body, err := json.Marshal(reminder)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+type SMTPConfig struct {
+	Addr string
+	From string
+	// To is the recipient address; "{username}" in it is replaced with the
+	// username of the reminder's recipient.
+	To       string
+	Username string
+	Password string
+	// Timeout bounds the whole exchange with the server, from dialling to
+	// QUIT.
+	Timeout time.Duration
+}
+
+type smtpNotifier struct {
+	config SMTPConfig
+}
+
+// NewSMTPNotifier mails reminders through an SMTP server. Authentication is
+// only attempted when a username is configured.
+func NewSMTPNotifier(config SMTPConfig) Notifier {
+	return &smtpNotifier{config: config}
+}
+
+func (n *smtpNotifier) Notify(ctx context.Context, reminder domain.Reminder) error {
+	to := n.config.To
+	if strings.Contains(to, "{username}") {
+		if reminder.Recipient == "" {
+			return fmt.Errorf("smtp: %w", ErrNoRecipient)
+		}
+		to = strings.ReplaceAll(to, "{username}", reminder.Recipient)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", reminderSubject(reminder))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "Task: %s\r\nID: %s\r\nDue: %s\r\n", reminder.Title, reminder.TaskID, reminder.DueDate.Format(time.RFC3339))
+
+	if err := n.send(ctx, to, msg.Bytes()); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	return nil
+}
+
+// send does what smtp.SendMail does, but on a connection that honours ctx
+// and the configured timeout.
+func (n *smtpNotifier) send(ctx context.Context, to string, msg []byte) error {
+	host, _, err := net.SplitHostPort(n.config.Addr)
+	if err != nil {
+		return err
+	}
+
+	if n.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.config.Timeout)
+		defer cancel()
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.config.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	// Closing the connection unblocks the exchange when ctx is cancelled.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.config.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("server does not support AUTH")
+		}
+		if err := client.Auth(smtp.PlainAuth("", n.config.Username, n.config.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(n.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func reminderSubject(reminder domain.Reminder) string {
+	due := reminder.DueDate.Format(time.RFC3339)
+	if reminder.Kind == domain.ReminderOverdue {
+		return fmt.Sprintf("Task %q is overdue (was due %s)", reminder.Title, due)
+	}
+	return fmt.Sprintf("Task %q is due within %s (%s)", reminder.Title, reminder.Window, due)
+}
@@ -1,6 +1,13 @@
 package infrastructure
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 type PasswordService struct{}
 
@@ -19,3 +26,29 @@ func (ps *PasswordService) HashPassword(password string) (string, error) {
 func (ps *PasswordService) ComparePassword(hashedPassword, password string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
+
+// LoadPasswordDenylist reads one breached or common password per line.
+// Blank lines and lines starting with '#' are ignored; entries are matched
+// case-insensitively.
+func LoadPasswordDenylist(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading password denylist: %w", err)
+	}
+	defer file.Close()
+
+	denylist := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		denylist[strings.ToLower(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading password denylist: %w", err)
+	}
+
+	return denylist, nil
+}
@@ -0,0 +1,150 @@
+package infrastructure
+
+import (
+	"context"
+	"log"
+	"math"
+	"strconv"
+	"sync"
+	"task_manager/Domain"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitStore keeps token buckets. The in-memory store limits each
+// instance on its own; a store backed by a shared database or cache makes
+// the limits hold across instances.
+type RateLimitStore interface {
+	// Take removes one token from the bucket for key and reports whether
+	// the request may proceed.
+	Take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (RateLimitResult, error)
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type RateLimiter struct {
+	store  RateLimitStore
+	limits map[string]domain.RateLimit
+}
+
+func NewRateLimiter(store RateLimitStore, limits map[string]domain.RateLimit) *RateLimiter {
+	return &RateLimiter{store: store, limits: limits}
+}
+
+// Limit applies the limit configured for group. Authenticated requests are
+// counted per user, anonymous ones per client IP, which honours
+// X-Forwarded-For only from the router's trusted proxies. If the store fails
+// the request is let through.
+func (rl *RateLimiter) Limit(group string) gin.HandlerFunc {
+	limit := rl.limits[group]
+	if !limit.Enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	policy := strconv.Itoa(limit.Requests) + ";w=" + strconv.Itoa(int(limit.Period.Seconds()))
+
+	return func(c *gin.Context) {
+		key := group + ":ip:" + c.ClientIP()
+		if userID := c.GetString("user_id"); userID != "" {
+			key = group + ":user:" + userID
+		}
+
+		result, err := rl.store.Take(c.Request.Context(), key, limit, time.Now())
+		if err != nil {
+			log.Printf("rate limit %s: %v", key, err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		if !result.Allowed {
+			c.Error(&domain.Error{
+				Kind:    domain.ErrTooManyRequests,
+				Message: "rate limit exceeded, try again later",
+				Details: map[string]interface{}{
+					"code":        "rate_limited",
+					"retry_after": ceilSeconds(result.RetryAfter),
+				},
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+const rateLimitSweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *memoryRateLimitStore) Take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Period.Seconds()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	result := RateLimitResult{Allowed: b.tokens >= 1}
+	if result.Allowed {
+		b.tokens--
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((capacity - b.tokens) / rate)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+// sweep drops buckets that have refilled completely, since a fresh bucket
+// is equivalent.
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,73 @@
+package infrastructure
+
+import (
+	"context"
+	"task_manager/Domain"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStoreTake(t *testing.T) {
+	limit := domain.RateLimit{Requests: 3, Period: 3 * time.Second}
+	start := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name   string
+		key    string
+		offset time.Duration
+		want   RateLimitResult
+	}{
+		{name: "first", offset: 0, want: RateLimitResult{Allowed: true, Remaining: 2, Reset: time.Second}},
+		{name: "second", offset: 0, want: RateLimitResult{Allowed: true, Remaining: 1, Reset: 2 * time.Second}},
+		{name: "burst used up", offset: 0, want: RateLimitResult{Allowed: true, Remaining: 0, Reset: 3 * time.Second}},
+		{name: "denied", offset: 0, want: RateLimitResult{Allowed: false, Remaining: 0, Reset: 3 * time.Second, RetryAfter: time.Second}},
+		{name: "other key", key: "other", offset: 0, want: RateLimitResult{Allowed: true, Remaining: 2, Reset: time.Second}},
+		{name: "partly refilled", offset: 500 * time.Millisecond, want: RateLimitResult{Allowed: false, Remaining: 0, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+		{name: "one token refilled", offset: time.Second, want: RateLimitResult{Allowed: true, Remaining: 0, Reset: 3 * time.Second}},
+		{name: "refill stops at capacity", offset: time.Hour, want: RateLimitResult{Allowed: true, Remaining: 2, Reset: time.Second}},
+	}
+
+	store := NewMemoryRateLimitStore()
+	for _, step := range steps {
+		key := step.key
+		if key == "" {
+			key = "key"
+		}
+		got, err := store.Take(context.Background(), key, limit, start.Add(step.offset))
+		if err != nil {
+			t.Fatalf("%s: Take() error = %v", step.name, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: Take() = %+v, want %+v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestMemoryRateLimitStoreSweep(t *testing.T) {
+	limit := domain.RateLimit{Requests: 2, Period: time.Second}
+	start := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	store := NewMemoryRateLimitStore().(*memoryRateLimitStore)
+	for _, key := range []string{"a", "b"} {
+		if _, err := store.Take(context.Background(), key, limit, start); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// b stays drained while a refills.
+	later := start.Add(rateLimitSweepInterval)
+	for i := 0; i < 2; i++ {
+		if _, err := store.Take(context.Background(), "b", limit, later.Add(-time.Millisecond)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := store.Take(context.Background(), "c", limit, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.buckets["a"]; ok {
+		t.Error("the full bucket a was not swept")
+	}
+	if _, ok := store.buckets["b"]; !ok {
+		t.Error("the drained bucket b was swept")
+	}
+}
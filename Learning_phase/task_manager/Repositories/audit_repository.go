@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditRepository is append-only: entries can be added and queried but
+// never changed or removed.
+type AuditRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Append(ctx context.Context, entry domain.AuditEntry) (domain.AuditEntry, error)
+	Find(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEntry, error)
+}
+
+type auditRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewAuditRepository(client *mongo.Client, dbName, collectionName string, timeout time.Duration) AuditRepository {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &auditRepository{collection: collection, timeout: timeout}
+}
+
+// EnsureIndexes covers the filters of GET /audit, which all sort by
+// timestamp. Both actor fields are indexed because an actor filter matches
+// either of them.
+func (r *auditRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_username", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+	})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	return nil
+}
+
+func (r *auditRepository) Append(ctx context.Context, entry domain.AuditEntry) (domain.AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	entry.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return domain.AuditEntry{}, domain.Internal(err)
+	}
+
+	return entry, nil
+}
+
+func (r *auditRepository) Find(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if query.Actor != "" {
+		filter["$or"] = bson.A{
+			bson.M{"actor_id": query.Actor},
+			bson.M{"actor_username": query.Actor},
+		}
+	}
+	if query.Action != "" {
+		filter["action"] = query.Action
+	}
+	if query.TargetType != "" {
+		filter["target_type"] = query.TargetType
+	}
+	if query.TargetID != "" {
+		filter["target_id"] = query.TargetID
+	}
+	timestamp := bson.M{}
+	if query.From != nil {
+		timestamp["$gte"] = *query.From
+	}
+	if query.To != nil {
+		timestamp["$lte"] = *query.To
+	}
+	if len(timestamp) > 0 {
+		filter["timestamp"] = timestamp
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}})
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []domain.AuditEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, domain.Internal(err)
+	}
+
+	if entries == nil {
+		entries = []domain.AuditEntry{}
+	}
+	return entries, nil
+}
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CommentRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	GetByTask(ctx context.Context, taskID string) ([]domain.Comment, error)
+	GetByID(ctx context.Context, id string) (domain.Comment, error)
+	Create(ctx context.Context, comment domain.Comment) (domain.Comment, error)
+	Update(ctx context.Context, id, body string, mentions []domain.Mention, updatedAt time.Time) (domain.Comment, error)
+	Delete(ctx context.Context, id string) error
+	DeleteByTasks(ctx context.Context, taskIDs []string) (int64, error)
+}
+
+type commentRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewCommentRepository(client *mongo.Client, dbName, collectionName string, timeout time.Duration) CommentRepository {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &commentRepository{collection: collection, timeout: timeout}
+}
+
+func (r *commentRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}},
+	})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	return nil
+}
+
+func (r *commentRepository) GetByTask(ctx context.Context, taskID string) ([]domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"task_id": taskID}, opts)
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	comments := []domain.Comment{}
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, domain.Internal(err)
+	}
+
+	return comments, nil
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, id string) (domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Comment{}, domain.InvalidID("comment")
+	}
+
+	var comment domain.Comment
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&comment)
+	if err == mongo.ErrNoDocuments {
+		return domain.Comment{}, domain.NotFound("comment")
+	}
+	if err != nil {
+		return domain.Comment{}, domain.Internal(err)
+	}
+
+	return comment, nil
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment domain.Comment) (domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	comment.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, comment)
+	if err != nil {
+		return domain.Comment{}, domain.Internal(err)
+	}
+
+	return comment, nil
+}
+
+func (r *commentRepository) Update(ctx context.Context, id, body string, mentions []domain.Mention, updatedAt time.Time) (domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Comment{}, domain.InvalidID("comment")
+	}
+
+	update := bson.M{"$set": bson.M{"body": body, "mentions": mentions, "updated_at": updatedAt}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var comment domain.Comment
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, update, opts).Decode(&comment)
+	if err == mongo.ErrNoDocuments {
+		return domain.Comment{}, domain.NotFound("comment")
+	}
+	if err != nil {
+		return domain.Comment{}, domain.Internal(err)
+	}
+
+	return comment, nil
+}
+
+func (r *commentRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.InvalidID("comment")
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.NotFound("comment")
+	}
+
+	return nil
+}
+
+func (r *commentRepository) DeleteByTasks(ctx context.Context, taskIDs []string) (int64, error) {
+	if len(taskIDs) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}})
+	if err != nil {
+		return 0, domain.Internal(err)
+	}
+
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LabelRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	GetAll(ctx context.Context) ([]domain.Label, error)
+	GetByID(ctx context.Context, id string) (domain.Label, error)
+	GetByName(ctx context.Context, name string) (domain.Label, error)
+	Create(ctx context.Context, label domain.Label) (domain.Label, error)
+	Update(ctx context.Context, id, name, color string) (domain.Label, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type labelRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewLabelRepository(client *mongo.Client, dbName, collectionName string, timeout time.Duration) LabelRepository {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &labelRepository{collection: collection, timeout: timeout}
+}
+
+func (r *labelRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	return nil
+}
+
+func (r *labelRepository) GetAll(ctx context.Context) ([]domain.Label, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	labels := []domain.Label{}
+	if err = cursor.All(ctx, &labels); err != nil {
+		return nil, domain.Internal(err)
+	}
+
+	return labels, nil
+}
+
+func (r *labelRepository) GetByID(ctx context.Context, id string) (domain.Label, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Label{}, domain.InvalidID("label")
+	}
+
+	return r.findOne(ctx, bson.M{"_id": objectID})
+}
+
+func (r *labelRepository) GetByName(ctx context.Context, name string) (domain.Label, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.findOne(ctx, bson.M{"name": name})
+}
+
+func (r *labelRepository) findOne(ctx context.Context, filter bson.M) (domain.Label, error) {
+	var label domain.Label
+	err := r.collection.FindOne(ctx, filter).Decode(&label)
+	if err == mongo.ErrNoDocuments {
+		return domain.Label{}, domain.NotFound("label")
+	}
+	if err != nil {
+		return domain.Label{}, domain.Internal(err)
+	}
+
+	return label, nil
+}
+
+func (r *labelRepository) Create(ctx context.Context, label domain.Label) (domain.Label, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	label.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, label)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.Label{}, errLabelExists(label.Name)
+	}
+	if err != nil {
+		return domain.Label{}, domain.Internal(err)
+	}
+
+	return label, nil
+}
+
+func (r *labelRepository) Update(ctx context.Context, id, name, color string) (domain.Label, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Label{}, domain.InvalidID("label")
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var label domain.Label
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"name": name, "color": color}}, opts).Decode(&label)
+	if err == mongo.ErrNoDocuments {
+		return domain.Label{}, domain.NotFound("label")
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.Label{}, errLabelExists(name)
+	}
+	if err != nil {
+		return domain.Label{}, domain.Internal(err)
+	}
+
+	return label, nil
+}
+
+func (r *labelRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.InvalidID("label")
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.NotFound("label")
+	}
+
+	return nil
+}
+
+func errLabelExists(name string) error {
+	return domain.Conflict(fmt.Sprintf("label %q already exists", name))
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LoginThrottleRepository stores failed-login counters for client IPs and
+// unknown usernames, so they survive restarts and are shared between
+// instances just like the lockouts kept on user records.
+type LoginThrottleRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// LockedUntil returns when the lock on key ends; the zero time if it
+	// is not locked.
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// RecordFailure counts a failed login for key at the given time,
+	// starting over if the previous one was before windowStart, and returns
+	// the new count. The entry is kept until expiresAt.
+	RecordFailure(ctx context.Context, key string, at, windowStart, expiresAt time.Time) (int, error)
+	LockUntil(ctx context.Context, key string, until time.Time) error
+}
+
+type loginThrottleRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewLoginThrottleRepository(client *mongo.Client, dbName, collectionName string, timeout time.Duration) LoginThrottleRepository {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &loginThrottleRepository{collection: collection, timeout: timeout}
+}
+
+// EnsureIndexes lets MongoDB remove entries once they have expired.
+func (r *loginThrottleRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	return nil
+}
+
+func (r *loginThrottleRepository) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var entry domain.LoginThrottle
+	err := r.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, domain.Internal(err)
+	}
+
+	return entry.LockedUntil, nil
+}
+
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, key string, at, windowStart, expiresAt time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"failures": bson.M{"$cond": bson.A{
+			bson.M{"$lt": bson.A{bson.M{"$ifNull": bson.A{"$last_failure", windowStart}}, windowStart}},
+			1,
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failures", 0}}, 1}},
+		}},
+		"last_failure": at,
+		"expires_at":   bson.M{"$max": bson.A{bson.M{"$ifNull": bson.A{"$expires_at", expiresAt}}, expiresAt}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var entry domain.LoginThrottle
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&entry); err != nil {
+		return 0, domain.Internal(err)
+	}
+
+	return entry.Failures, nil
+}
+
+func (r *loginThrottleRepository) LockUntil(ctx context.Context, key string, until time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key}, bson.M{
+		"$set": bson.M{"locked_until": until},
+		"$max": bson.M{"expires_at": until},
+	})
+	if err != nil {
+		return domain.Internal(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []domain.AuditEntry
+}
+
+func NewMemoryAuditRepository() AuditRepository {
+	return &memoryAuditRepository{}
+}
+
+func (r *memoryAuditRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryAuditRepository) Append(ctx context.Context, entry domain.AuditEntry) (domain.AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = primitive.NewObjectID()
+	r.entries = append(r.entries, entry)
+
+	return entry, nil
+}
+
+func (r *memoryAuditRepository) Find(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := []domain.AuditEntry{}
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if query.Limit > 0 && len(entries) == query.Limit {
+			break
+		}
+		entry := r.entries[i]
+		if matchesAuditQuery(entry, query) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func matchesAuditQuery(entry domain.AuditEntry, query domain.AuditQuery) bool {
+	if query.Actor != "" && entry.ActorID != query.Actor && entry.ActorUsername != query.Actor {
+		return false
+	}
+	if query.Action != "" && entry.Action != query.Action {
+		return false
+	}
+	if query.TargetType != "" && entry.TargetType != query.TargetType {
+		return false
+	}
+	if query.TargetID != "" && entry.TargetID != query.TargetID {
+		return false
+	}
+	if query.From != nil && entry.Timestamp.Before(*query.From) {
+		return false
+	}
+	if query.To != nil && entry.Timestamp.After(*query.To) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryCommentRepository struct {
+	mu       sync.RWMutex
+	comments map[primitive.ObjectID]domain.Comment
+}
+
+func NewMemoryCommentRepository() CommentRepository {
+	return &memoryCommentRepository{comments: make(map[primitive.ObjectID]domain.Comment)}
+}
+
+func (r *memoryCommentRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryCommentRepository) GetByTask(ctx context.Context, taskID string) ([]domain.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := []domain.Comment{}
+	for _, comment := range r.comments {
+		if comment.TaskID == taskID {
+			comments = append(comments, comment)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID.Hex() < comments[j].ID.Hex()
+	})
+
+	return comments, nil
+}
+
+func (r *memoryCommentRepository) GetByID(ctx context.Context, id string) (domain.Comment, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Comment{}, domain.InvalidID("comment")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comment, ok := r.comments[objectID]
+	if !ok {
+		return domain.Comment{}, domain.NotFound("comment")
+	}
+
+	return comment, nil
+}
+
+func (r *memoryCommentRepository) Create(ctx context.Context, comment domain.Comment) (domain.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment.ID = primitive.NewObjectID()
+	r.comments[comment.ID] = comment
+
+	return comment, nil
+}
+
+func (r *memoryCommentRepository) Update(ctx context.Context, id, body string, mentions []domain.Mention, updatedAt time.Time) (domain.Comment, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Comment{}, domain.InvalidID("comment")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment, ok := r.comments[objectID]
+	if !ok {
+		return domain.Comment{}, domain.NotFound("comment")
+	}
+	comment.Body = body
+	comment.Mentions = mentions
+	comment.UpdatedAt = &updatedAt
+	r.comments[objectID] = comment
+
+	return comment, nil
+}
+
+func (r *memoryCommentRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.InvalidID("comment")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.comments[objectID]; !ok {
+		return domain.NotFound("comment")
+	}
+	delete(r.comments, objectID)
+
+	return nil
+}
+
+func (r *memoryCommentRepository) DeleteByTasks(ctx context.Context, taskIDs []string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := make(map[string]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		tasks[id] = true
+	}
+
+	var deleted int64
+	for id, comment := range r.comments {
+		if tasks[comment.TaskID] {
+			delete(r.comments, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryLabelRepository struct {
+	mu     sync.RWMutex
+	labels map[primitive.ObjectID]domain.Label
+}
+
+func NewMemoryLabelRepository() LabelRepository {
+	return &memoryLabelRepository{labels: make(map[primitive.ObjectID]domain.Label)}
+}
+
+func (r *memoryLabelRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryLabelRepository) GetAll(ctx context.Context) ([]domain.Label, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	labels := make([]domain.Label, 0, len(r.labels))
+	for _, label := range r.labels {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+
+	return labels, nil
+}
+
+func (r *memoryLabelRepository) GetByID(ctx context.Context, id string) (domain.Label, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Label{}, domain.InvalidID("label")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	label, ok := r.labels[objectID]
+	if !ok {
+		return domain.Label{}, domain.NotFound("label")
+	}
+
+	return label, nil
+}
+
+func (r *memoryLabelRepository) GetByName(ctx context.Context, name string) (domain.Label, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if label, ok := r.findByName(name); ok {
+		return label, nil
+	}
+	return domain.Label{}, domain.NotFound("label")
+}
+
+func (r *memoryLabelRepository) findByName(name string) (domain.Label, bool) {
+	for _, label := range r.labels {
+		if label.Name == name {
+			return label, true
+		}
+	}
+	return domain.Label{}, false
+}
+
+func (r *memoryLabelRepository) Create(ctx context.Context, label domain.Label) (domain.Label, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.findByName(label.Name); exists {
+		return domain.Label{}, errLabelExists(label.Name)
+	}
+	label.ID = primitive.NewObjectID()
+	r.labels[label.ID] = label
+
+	return label, nil
+}
+
+func (r *memoryLabelRepository) Update(ctx context.Context, id, name, color string) (domain.Label, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Label{}, domain.InvalidID("label")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	label, ok := r.labels[objectID]
+	if !ok {
+		return domain.Label{}, domain.NotFound("label")
+	}
+	if other, exists := r.findByName(name); exists && other.ID != objectID {
+		return domain.Label{}, errLabelExists(name)
+	}
+	label.Name = name
+	label.Color = color
+	r.labels[objectID] = label
+
+	return label, nil
+}
+
+func (r *memoryLabelRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.InvalidID("label")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.labels[objectID]; !ok {
+		return domain.NotFound("label")
+	}
+	delete(r.labels, objectID)
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"task_manager/Domain"
+	"time"
+)
+
+type memoryLoginThrottleRepository struct {
+	mu        sync.Mutex
+	entries   map[string]domain.LoginThrottle
+	lastPrune time.Time
+}
+
+func NewMemoryLoginThrottleRepository() LoginThrottleRepository {
+	return &memoryLoginThrottleRepository{entries: make(map[string]domain.LoginThrottle)}
+}
+
+func (r *memoryLoginThrottleRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryLoginThrottleRepository) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.entries[key].LockedUntil, nil
+}
+
+func (r *memoryLoginThrottleRepository) RecordFailure(ctx context.Context, key string, at, windowStart, expiresAt time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prune(at)
+	entry, ok := r.entries[key]
+	if !ok {
+		entry = domain.LoginThrottle{Key: key}
+	}
+	if entry.LastFailure.Before(windowStart) {
+		entry.Failures = 0
+	}
+	entry.Failures++
+	entry.LastFailure = at
+	if expiresAt.After(entry.ExpiresAt) {
+		entry.ExpiresAt = expiresAt
+	}
+	r.entries[key] = entry
+
+	return entry.Failures, nil
+}
+
+func (r *memoryLoginThrottleRepository) LockUntil(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[key]
+	if !ok {
+		return nil
+	}
+	entry.LockedUntil = until
+	if until.After(entry.ExpiresAt) {
+		entry.ExpiresAt = until
+	}
+	r.entries[key] = entry
+
+	return nil
+}
+
+// prune drops expired entries, at most once a minute.
+func (r *memoryLoginThrottleRepository) prune(now time.Time) {
+	if now.Sub(r.lastPrune) < time.Minute {
+		return
+	}
+	r.lastPrune = now
+	for key, entry := range r.entries {
+		if now.After(entry.ExpiresAt) {
+			delete(r.entries, key)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryProjectRepository struct {
+	mu       sync.RWMutex
+	projects map[primitive.ObjectID]domain.Project
+}
+
+func NewMemoryProjectRepository() ProjectRepository {
+	return &memoryProjectRepository{projects: make(map[primitive.ObjectID]domain.Project)}
+}
+
+func (r *memoryProjectRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryProjectRepository) GetAll(ctx context.Context, memberID string) ([]domain.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	projects := []domain.Project{}
+	for _, project := range r.projects {
+		if _, ok := project.Member(memberID); memberID == "" || ok {
+			projects = append(projects, project)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Key < projects[j].Key })
+
+	return projects, nil
+}
+
+func (r *memoryProjectRepository) GetByID(ctx context.Context, id string) (domain.Project, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Project{}, domain.InvalidID("project")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[objectID]
+	if !ok {
+		return domain.Project{}, domain.NotFound("project")
+	}
+
+	return project, nil
+}
+
+func (r *memoryProjectRepository) GetByKey(ctx context.Context, key string) (domain.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, project := range r.projects {
+		if project.Key == key {
+			return project, nil
+		}
+	}
+
+	return domain.Project{}, domain.NotFound("project")
+}
+
+func (r *memoryProjectRepository) Create(ctx context.Context, project domain.Project) (domain.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.projects {
+		if existing.Key == project.Key {
+			return domain.Project{}, errProjectKeyTaken(project.Key)
+		}
+	}
+	project.ID = primitive.NewObjectID()
+	project.Members = append([]domain.ProjectMember{}, project.Members...)
+	r.projects[project.ID] = project
+
+	return project, nil
+}
+
+func (r *memoryProjectRepository) Update(ctx context.Context, id, name, description string) (domain.Project, error) {
+	return r.update(id, func(project *domain.Project) {
+		project.Name = name
+		project.Description = description
+	})
+}
+
+func (r *memoryProjectRepository) SetMember(ctx context.Context, id string, member domain.ProjectMember) (domain.Project, error) {
+	return r.update(id, func(project *domain.Project) {
+		for i, m := range project.Members {
+			if m.UserID == member.UserID {
+				project.Members[i].Role = member.Role
+				return
+			}
+		}
+		project.Members = append(project.Members, member)
+	})
+}
+
+func (r *memoryProjectRepository) RemoveMember(ctx context.Context, id, userID string) (domain.Project, error) {
+	return r.update(id, func(project *domain.Project) {
+		members := []domain.ProjectMember{}
+		for _, m := range project.Members {
+			if m.UserID != userID {
+				members = append(members, m)
+			}
+		}
+		project.Members = members
+	})
+}
+
+func (r *memoryProjectRepository) update(id string, apply func(project *domain.Project)) (domain.Project, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Project{}, domain.InvalidID("project")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[objectID]
+	if !ok {
+		return domain.Project{}, domain.NotFound("project")
+	}
+	project.Members = append([]domain.ProjectMember{}, project.Members...)
+	apply(&project)
+	r.projects[objectID] = project
+
+	return project, nil
+}
+
+func (r *memoryProjectRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.InvalidID("project")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.projects[objectID]; !ok {
+		return domain.NotFound("project")
+	}
+	delete(r.projects, objectID)
+
+	return nil
+}
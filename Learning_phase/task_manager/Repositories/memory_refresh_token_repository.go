@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[primitive.ObjectID]domain.RefreshToken
+}
+
+func NewMemoryRefreshTokenRepository() RefreshTokenRepository {
+	return &memoryRefreshTokenRepository{tokens: make(map[primitive.ObjectID]domain.RefreshToken)}
+}
+
+func (r *memoryRefreshTokenRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) Create(ctx context.Context, token domain.RefreshToken) (domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = primitive.NewObjectID()
+	r.tokens[token.ID] = token
+
+	return token, nil
+}
+
+func (r *memoryRefreshTokenRepository) GetByHash(ctx context.Context, hash string) (domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			return token, nil
+		}
+	}
+
+	return domain.RefreshToken{}, domain.NotFound("refresh token")
+}
+
+func (r *memoryRefreshTokenRepository) Revoke(ctx context.Context, id, reason string) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, domain.InvalidID("refresh token")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[objectID]
+	if !ok || token.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.RevokedAt, token.RevokeReason = &now, reason
+	r.tokens[objectID] = token
+
+	return true, nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID, reason string) error {
+	r.revokeWhere(func(token domain.RefreshToken) bool { return token.FamilyID == familyID }, reason)
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID, reason string) error {
+	r.revokeWhere(func(token domain.RefreshToken) bool { return token.UserID == userID }, reason)
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) revokeWhere(match func(domain.RefreshToken) bool, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range r.tokens {
+		if token.RevokedAt == nil && match(token) {
+			token.RevokedAt, token.RevokeReason = &now, reason
+			r.tokens[id] = token
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"task_manager/Domain"
+	"time"
+)
+
+type reminderID struct {
+	taskID  string
+	kind    string
+	window  string
+	dueDate time.Time
+}
+
+type memoryReminderRepository struct {
+	mu        sync.Mutex
+	reminders map[reminderID]domain.Reminder
+}
+
+func NewMemoryReminderRepository() ReminderRepository {
+	return &memoryReminderRepository{reminders: make(map[reminderID]domain.Reminder)}
+}
+
+func (r *memoryReminderRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryReminderRepository) Claim(ctx context.Context, reminder domain.Reminder) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := memoryReminderID(reminder)
+	if _, ok := r.reminders[id]; ok {
+		return false, nil
+	}
+	r.reminders[id] = reminder
+
+	return true, nil
+}
+
+func (r *memoryReminderRepository) Release(ctx context.Context, reminder domain.Reminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.reminders, memoryReminderID(reminder))
+	return nil
+}
+
+func (r *memoryReminderRepository) Recorded(ctx context.Context, taskIDs []string) ([]domain.Reminder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := make(map[string]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		tasks[id] = true
+	}
+
+	reminders := []domain.Reminder{}
+	for id, reminder := range r.reminders {
+		if tasks[id.taskID] {
+			reminders = append(reminders, reminder)
+		}
+	}
+	return reminders, nil
+}
+
+func (r *memoryReminderRepository) DeleteByTasks(ctx context.Context, taskIDs []string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := make(map[string]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		tasks[id] = true
+	}
+
+	var deleted int64
+	for id := range r.reminders {
+		if tasks[id.taskID] {
+			delete(r.reminders, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func memoryReminderID(reminder domain.Reminder) reminderID {
+	return reminderID{taskID: reminder.TaskID, kind: reminder.Kind, window: reminder.Window, dueDate: reminder.DueDate.UTC()}
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"task_manager/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRoleRepository struct {
+	mu    sync.RWMutex
+	roles map[string]domain.Role
+}
+
+func NewMemoryRoleRepository() RoleRepository {
+	return &memoryRoleRepository{roles: make(map[string]domain.Role)}
+}
+
+func (r *memoryRoleRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryRoleRepository) GetAll(ctx context.Context) ([]domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	roles := make([]domain.Role, 0, len(r.roles))
+	for _, role := range r.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+
+	return roles, nil
+}
+
+func (r *memoryRoleRepository) GetByName(ctx context.Context, name string) (domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	role, ok := r.roles[name]
+	if !ok {
+		return domain.Role{}, domain.NotFound("role")
+	}
+
+	return role, nil
+}
+
+func (r *memoryRoleRepository) Create(ctx context.Context, role domain.Role) (domain.Role, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.roles[role.Name]; exists {
+		return domain.Role{}, errRoleExists(role.Name)
+	}
+	role.ID = primitive.NewObjectID()
+	r.roles[role.Name] = role
+
+	return role, nil
+}
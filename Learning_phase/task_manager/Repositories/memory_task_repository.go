@@ -0,0 +1,499 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTaskRepository struct {
+	mu    sync.RWMutex
+	tasks map[primitive.ObjectID]domain.Task
+}
+
+func NewMemoryTaskRepository() TaskRepository {
+	return &memoryTaskRepository{tasks: make(map[primitive.ObjectID]domain.Task)}
+}
+
+func (r *memoryTaskRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryTaskRepository) GetAll(ctx context.Context, query domain.TaskQuery) ([]domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sortField, ok := taskSortFields[query.SortBy]
+	if !ok {
+		sortField = "_id"
+	}
+	less := func(a, b taskSortKey) bool {
+		c := a.compare(b)
+		if query.SortDesc {
+			return c > 0
+		}
+		return c < 0
+	}
+
+	var cursorKey taskSortKey
+	if query.Cursor != nil {
+		cursorKey = taskSortKey{value: query.Cursor.Value, id: query.Cursor.ID}
+		if sortField == "_id" {
+			cursorKey.value = query.Cursor.ID.Hex()
+		}
+	}
+
+	tasks := make([]domain.Task, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		if !matchesTaskQuery(task, query) {
+			continue
+		}
+		if query.Cursor != nil && !less(cursorKey, newTaskSortKey(task, sortField)) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return less(newTaskSortKey(tasks[i], sortField), newTaskSortKey(tasks[j], sortField))
+	})
+
+	if query.Limit > 0 && len(tasks) > query.Limit {
+		tasks = tasks[:query.Limit]
+	}
+	return tasks, nil
+}
+
+func (r *memoryTaskRepository) GetByID(ctx context.Context, id string) (domain.Task, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Task{}, domain.InvalidID("task")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[objectID]
+	if !ok || task.DeletedAt != nil {
+		return domain.Task{}, domain.NotFound("task")
+	}
+
+	return task, nil
+}
+
+func (r *memoryTaskRepository) Create(ctx context.Context, task domain.Task) (domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task.ID = primitive.NewObjectID()
+	task.Version = 1
+	r.tasks[task.ID] = task
+
+	return task, nil
+}
+
+func (r *memoryTaskRepository) Update(ctx context.Context, id string, updatedTask domain.Task, version int64) (domain.Task, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Task{}, domain.InvalidID("task")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.tasks[objectID]
+	if !ok || existing.DeletedAt != nil {
+		return domain.Task{}, domain.NotFound("task")
+	}
+	if version != domain.AnyVersion && existing.Version != version {
+		return domain.Task{}, domain.ErrVersionMismatch
+	}
+
+	existing.Title = updatedTask.Title
+	existing.Description = updatedTask.Description
+	existing.DueDate = updatedTask.DueDate
+	existing.Status = updatedTask.Status
+	existing.StatusHistory = updatedTask.StatusHistory
+	existing.AssigneeID = updatedTask.AssigneeID
+	existing.Priority = updatedTask.Priority
+	existing.EstimateMinutes = updatedTask.EstimateMinutes
+	existing.Version++
+	r.tasks[objectID] = existing
+
+	return existing, nil
+}
+
+func (r *memoryTaskRepository) Patch(ctx context.Context, id string, patch domain.TaskPatch, version int64) (domain.Task, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Task{}, domain.InvalidID("task")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[objectID]
+	if !ok || task.DeletedAt != nil {
+		return domain.Task{}, domain.NotFound("task")
+	}
+	if version != domain.AnyVersion && task.Version != version {
+		return domain.Task{}, domain.ErrVersionMismatch
+	}
+
+	if patch.Title != nil {
+		task.Title = *patch.Title
+	}
+	if patch.Description != nil {
+		task.Description = *patch.Description
+	}
+	if patch.DueDate != nil {
+		task.DueDate = *patch.DueDate
+	}
+	if patch.Status != nil {
+		task.Status = *patch.Status
+		task.StatusHistory = patch.StatusHistory
+	}
+	if patch.AssigneeID != nil {
+		task.AssigneeID = *patch.AssigneeID
+	}
+	if patch.Priority != nil {
+		task.Priority = *patch.Priority
+	}
+	if patch.EstimateMinutes != nil {
+		task.EstimateMinutes = *patch.EstimateMinutes
+	}
+	if patch.Recurrence != nil {
+		task.Recurrence = patch.Recurrence
+	}
+	task.Version++
+	r.tasks[objectID] = task
+
+	return task, nil
+}
+
+func (r *memoryTaskRepository) Delete(ctx context.Context, id, deletedBy string, version int64) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.InvalidID("task")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[objectID]
+	if !ok || task.DeletedAt != nil {
+		return domain.NotFound("task")
+	}
+	if version != domain.AnyVersion && task.Version != version {
+		return domain.ErrVersionMismatch
+	}
+
+	now := time.Now().UTC()
+	task.DeletedAt = &now
+	task.DeletedBy = deletedBy
+	task.Version++
+	r.tasks[objectID] = task
+
+	return nil
+}
+
+func (r *memoryTaskRepository) Restore(ctx context.Context, id string) (domain.Task, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Task{}, domain.InvalidID("task")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[objectID]
+	if !ok || task.DeletedAt == nil {
+		return domain.Task{}, domain.NotFound("task")
+	}
+
+	task.DeletedAt = nil
+	task.DeletedBy = ""
+	task.Version++
+	r.tasks[objectID] = task
+
+	return task, nil
+}
+
+func (r *memoryTaskRepository) Purge(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged []string
+	for id, task := range r.tasks {
+		if task.DeletedAt != nil && task.DeletedAt.Before(deletedBefore) {
+			delete(r.tasks, id)
+			purged = append(purged, task.ID.Hex())
+		}
+	}
+
+	return purged, nil
+}
+
+func matchesTaskQuery(task domain.Task, query domain.TaskQuery) bool {
+	if query.Deleted != (task.DeletedAt != nil) {
+		return false
+	}
+	if query.OwnerID != "" && task.CreatedBy != query.OwnerID && task.AssigneeID != query.OwnerID {
+		return false
+	}
+	if query.Status != "" && task.Status != query.Status {
+		return false
+	}
+	if containsString(query.ExcludeStatuses, task.Status) {
+		return false
+	}
+	if query.Priority != "" && task.Priority != query.Priority {
+		return false
+	}
+	if query.ProjectID != "" && task.ProjectID != query.ProjectID {
+		return false
+	}
+	if query.NoProject && task.ProjectID != "" {
+		return false
+	}
+	if query.ParentID != "" && task.ParentID != query.ParentID {
+		return false
+	}
+	if query.BlockedBy != "" && !containsString(task.BlockedBy, query.BlockedBy) {
+		return false
+	}
+	if query.SeriesID != "" && (task.Recurrence == nil || task.Recurrence.SeriesID != query.SeriesID) {
+		return false
+	}
+	if len(query.Labels) > 0 && !matchesLabels(task.Labels, query.Labels, query.LabelMatch == domain.LabelMatchAll) {
+		return false
+	}
+	if query.DueAfter != nil && task.DueDate.Before(*query.DueAfter) {
+		return false
+	}
+	if query.DueBefore != nil && task.DueDate.After(*query.DueBefore) {
+		return false
+	}
+	if query.Title != "" && !strings.Contains(strings.ToLower(task.Title), strings.ToLower(query.Title)) {
+		return false
+	}
+	return true
+}
+
+func matchesLabels(labels, wanted []string, all bool) bool {
+	for _, label := range wanted {
+		if containsString(labels, label) != all {
+			return !all
+		}
+	}
+	return all
+}
+
+type taskSortKey struct {
+	value interface{}
+	id    primitive.ObjectID
+}
+
+func newTaskSortKey(task domain.Task, field string) taskSortKey {
+	key := taskSortKey{id: task.ID}
+	switch field {
+	case "due_date":
+		key.value = task.DueDate
+	case "title":
+		key.value = task.Title
+	case "status":
+		key.value = task.Status
+	default:
+		key.value = task.ID.Hex()
+	}
+	return key
+}
+
+func (k taskSortKey) compare(other taskSortKey) int {
+	var c int
+	switch v := k.value.(type) {
+	case time.Time:
+		if o, ok := other.value.(time.Time); ok {
+			c = v.Compare(o)
+		}
+	case string:
+		if o, ok := other.value.(string); ok {
+			c = strings.Compare(v, o)
+		}
+	}
+	if c == 0 {
+		c = strings.Compare(k.id.Hex(), other.id.Hex())
+	}
+	return c
+}
+
+func (r *memoryTaskRepository) SetParent(ctx context.Context, id, parentID string, version int64) (domain.Task, error) {
+	return r.updateEdges(id, version, func(task *domain.Task) {
+		task.ParentID = parentID
+	})
+}
+
+func (r *memoryTaskRepository) AddBlocker(ctx context.Context, id, blockerID string, version int64) (domain.Task, error) {
+	return r.updateEdges(id, version, func(task *domain.Task) {
+		if !containsString(task.BlockedBy, blockerID) {
+			task.BlockedBy = append(append([]string{}, task.BlockedBy...), blockerID)
+		}
+	})
+}
+
+func (r *memoryTaskRepository) RemoveBlocker(ctx context.Context, id, blockerID string, version int64) (domain.Task, error) {
+	return r.updateEdges(id, version, func(task *domain.Task) {
+		task.BlockedBy = removeString(task.BlockedBy, blockerID)
+	})
+}
+
+func (r *memoryTaskRepository) AddLabel(ctx context.Context, id, label string, version int64) (domain.Task, error) {
+	return r.updateEdges(id, version, func(task *domain.Task) {
+		if !containsString(task.Labels, label) {
+			task.Labels = append(append([]string{}, task.Labels...), label)
+		}
+	})
+}
+
+func (r *memoryTaskRepository) RemoveLabel(ctx context.Context, id, label string, version int64) (domain.Task, error) {
+	return r.updateEdges(id, version, func(task *domain.Task) {
+		task.Labels = removeString(task.Labels, label)
+	})
+}
+
+func (r *memoryTaskRepository) RenameLabel(ctx context.Context, oldName, newName string) (int64, error) {
+	return r.updateLabelled(oldName, func(task *domain.Task) {
+		if containsString(task.Labels, newName) {
+			task.Labels = removeString(task.Labels, oldName)
+			return
+		}
+		labels := make([]string, len(task.Labels))
+		for i, label := range task.Labels {
+			if label == oldName {
+				label = newName
+			}
+			labels[i] = label
+		}
+		task.Labels = labels
+	})
+}
+
+func (r *memoryTaskRepository) StripLabel(ctx context.Context, label string) (int64, error) {
+	return r.updateLabelled(label, func(task *domain.Task) {
+		task.Labels = removeString(task.Labels, label)
+	})
+}
+
+func (r *memoryTaskRepository) SetRecurrence(ctx context.Context, id string, recurrence *domain.Recurrence, version int64) (domain.Task, error) {
+	return r.updateEdges(id, version, func(task *domain.Task) {
+		task.Recurrence = recurrence
+	})
+}
+
+func (r *memoryTaskRepository) MarkNextOccurrence(ctx context.Context, id string, created bool) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, domain.InvalidID("task")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[objectID]
+	if !ok || task.Recurrence == nil || task.Recurrence.NextCreated == created {
+		return false, nil
+	}
+	recurrence := *task.Recurrence
+	recurrence.NextCreated = created
+	task.Recurrence = &recurrence
+	r.tasks[objectID] = task
+
+	return true, nil
+}
+
+func (r *memoryTaskRepository) GetPendingOccurrences(ctx context.Context, now time.Time, limit int) ([]domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := []domain.Task{}
+	for _, task := range r.tasks {
+		if task.DeletedAt != nil || task.Recurrence == nil || task.Recurrence.NextCreated || task.Status == domain.StatusArchived {
+			continue
+		}
+		if task.Status == domain.StatusDone || task.DueDate.Before(now) {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].DueDate.Before(tasks[j].DueDate)
+	})
+
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+func (r *memoryTaskRepository) updateLabelled(label string, apply func(task *domain.Task)) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var changed int64
+	for id, task := range r.tasks {
+		if !containsString(task.Labels, label) {
+			continue
+		}
+		apply(&task)
+		task.Version++
+		r.tasks[id] = task
+		changed++
+	}
+
+	return changed, nil
+}
+
+func (r *memoryTaskRepository) updateEdges(id string, version int64, apply func(task *domain.Task)) (domain.Task, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Task{}, domain.InvalidID("task")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.tasks[objectID]
+	if !ok || task.DeletedAt != nil {
+		return domain.Task{}, domain.NotFound("task")
+	}
+	if version != domain.AnyVersion && task.Version != version {
+		return domain.Task{}, domain.ErrVersionMismatch
+	}
+	apply(&task)
+	task.Version++
+	r.tasks[objectID] = task
+
+	return task, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(values []string, value string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
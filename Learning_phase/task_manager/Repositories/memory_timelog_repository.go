@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTimeLogRepository struct {
+	mu   sync.RWMutex
+	logs map[primitive.ObjectID]domain.TimeLog
+}
+
+func NewMemoryTimeLogRepository() TimeLogRepository {
+	return &memoryTimeLogRepository{logs: make(map[primitive.ObjectID]domain.TimeLog)}
+}
+
+func (r *memoryTimeLogRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryTimeLogRepository) GetByTask(ctx context.Context, taskID string) ([]domain.TimeLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	logs := []domain.TimeLog{}
+	for _, log := range r.logs {
+		if log.TaskID == taskID {
+			logs = append(logs, log)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if !logs[i].Start.Equal(logs[j].Start) {
+			return logs[i].Start.Before(logs[j].Start)
+		}
+		return logs[i].ID.Hex() < logs[j].ID.Hex()
+	})
+
+	return logs, nil
+}
+
+func (r *memoryTimeLogRepository) GetByID(ctx context.Context, id string) (domain.TimeLog, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.TimeLog{}, domain.InvalidID("time log")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	log, ok := r.logs[objectID]
+	if !ok {
+		return domain.TimeLog{}, domain.NotFound("time log")
+	}
+
+	return log, nil
+}
+
+func (r *memoryTimeLogRepository) GetRunning(ctx context.Context, userID string) (domain.TimeLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if log, ok := r.running(userID); ok {
+		return log, nil
+	}
+	return domain.TimeLog{}, domain.NotFound("running timer")
+}
+
+func (r *memoryTimeLogRepository) running(userID string) (domain.TimeLog, bool) {
+	for _, log := range r.logs {
+		if log.Running && log.UserID == userID {
+			return log, true
+		}
+	}
+	return domain.TimeLog{}, false
+}
+
+func (r *memoryTimeLogRepository) Create(ctx context.Context, log domain.TimeLog) (domain.TimeLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.running(log.UserID); ok && log.Running {
+		return domain.TimeLog{}, domain.ErrTimerRunning
+	}
+	log.ID = primitive.NewObjectID()
+	r.logs[log.ID] = log
+
+	return log, nil
+}
+
+func (r *memoryTimeLogRepository) Stop(ctx context.Context, id string, end time.Time, durationSeconds int64, note string) (domain.TimeLog, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.TimeLog{}, domain.InvalidID("time log")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log, ok := r.logs[objectID]
+	if !ok || !log.Running {
+		return domain.TimeLog{}, domain.NotFound("running timer")
+	}
+	log.Running = false
+	log.End = &end
+	log.DurationSeconds = durationSeconds
+	log.Note = note
+	r.logs[objectID] = log
+
+	return log, nil
+}
+
+func (r *memoryTimeLogRepository) Delete(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.InvalidID("time log")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.logs[objectID]; !ok {
+		return domain.NotFound("time log")
+	}
+	delete(r.logs, objectID)
+
+	return nil
+}
+
+func (r *memoryTimeLogRepository) DeleteByTasks(ctx context.Context, taskIDs []string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := make(map[string]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		tasks[id] = true
+	}
+
+	var deleted int64
+	for id, log := range r.logs {
+		if tasks[log.TaskID] {
+			delete(r.logs, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+func (r *memoryTimeLogRepository) TotalsByUser(ctx context.Context, query domain.TimeLogQuery) ([]domain.TimeTotal, error) {
+	return r.totals(query, func(log domain.TimeLog) domain.TimeTotal {
+		return domain.TimeTotal{UserID: log.UserID, Username: log.Username}
+	})
+}
+
+func (r *memoryTimeLogRepository) TotalsByTask(ctx context.Context, query domain.TimeLogQuery) ([]domain.TimeTotal, error) {
+	return r.totals(query, func(log domain.TimeLog) domain.TimeTotal {
+		return domain.TimeTotal{TaskID: log.TaskID}
+	})
+}
+
+func (r *memoryTimeLogRepository) totals(query domain.TimeLogQuery, group func(domain.TimeLog) domain.TimeTotal) ([]domain.TimeTotal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byKey := make(map[string]*domain.TimeTotal)
+	latest := make(map[string]time.Time)
+	for _, log := range r.logs {
+		if log.Running || (query.TaskID != "" && log.TaskID != query.TaskID) || (query.UserID != "" && log.UserID != query.UserID) {
+			continue
+		}
+		if (query.From != nil && log.Start.Before(*query.From)) || (query.To != nil && log.Start.After(*query.To)) {
+			continue
+		}
+		key := group(log)
+		total, ok := byKey[key.TaskID+key.UserID]
+		if !ok {
+			total = &key
+			byKey[key.TaskID+key.UserID] = total
+		}
+		// Like the Mongo pipeline, keep the username of the latest entry.
+		if !ok || log.Start.After(latest[key.TaskID+key.UserID]) {
+			total.Username = key.Username
+			latest[key.TaskID+key.UserID] = log.Start
+		}
+		total.TotalSeconds += log.DurationSeconds
+	}
+
+	totals := make([]domain.TimeTotal, 0, len(byKey))
+	for _, total := range byKey {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].TotalSeconds != totals[j].TotalSeconds {
+			return totals[i].TotalSeconds > totals[j].TotalSeconds
+		}
+		return totals[i].TaskID+totals[i].UserID < totals[j].TaskID+totals[j].UserID
+	})
+
+	return totals, nil
+}
@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[string]domain.User
+}
+
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: make(map[string]domain.User)}
+}
+
+func (r *memoryUserRepository) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user domain.User) (domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[user.Username]; exists {
+		return domain.User{}, errUsernameTaken()
+	}
+	user.ID = primitive.NewObjectID()
+	r.users[user.Username] = user
+
+	return user, nil
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id string) (domain.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.User{}, domain.InvalidID("user")
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.ID == objectID {
+			return user, nil
+		}
+	}
+
+	return domain.User{}, domain.NotFound("user")
+}
+
+func (r *memoryUserRepository) GetByUsername(ctx context.Context, username string) (domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[username]
+	if !ok {
+		return domain.User{}, domain.NotFound("user")
+	}
+
+	return user, nil
+}
+
+func (r *memoryUserRepository) GetByUsernames(ctx context.Context, usernames []string) ([]domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []domain.User
+	for _, username := range usernames {
+		if user, ok := r.users[username]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *memoryUserRepository) CountUsers(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.users)), nil
+}
+
+func (r *memoryUserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, user := range r.users {
+		if user.Role == role {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *memoryUserRepository) SetRole(ctx context.Context, username, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[username]
+	if !ok {
+		return domain.NotFound("user")
+	}
+	user.Role = role
+	r.users[username] = user
+
+	return nil
+}
+
+func (r *memoryUserRepository) RecordLoginFailure(ctx context.Context, username string, at, windowStart time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[username]
+	if !ok {
+		return 0, domain.NotFound("user")
+	}
+	if user.LastFailedLogin == nil || user.LastFailedLogin.Before(windowStart) {
+		user.FailedLogins = 0
+	}
+	user.FailedLogins++
+	user.LastFailedLogin = &at
+	r.users[username] = user
+
+	return user.FailedLogins, nil
+}
+
+func (r *memoryUserRepository) LockUntil(ctx context.Context, username string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[username]
+	if !ok {
+		return domain.NotFound("user")
+	}
+	user.LockedUntil = &until
+	r.users[username] = user
+
+	return nil
+}
+
+func (r *memoryUserRepository) ResetLoginFailures(ctx context.Context, username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[username]
+	if !ok {
+		return domain.NotFound("user")
+	}
+	user.FailedLogins = 0
+	user.LastFailedLogin = nil
+	user.LockedUntil = nil
+	r.users[username] = user
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ProjectRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// GetAll lists the projects memberID belongs to, or every project when
+	// memberID is empty.
+	GetAll(ctx context.Context, memberID string) ([]domain.Project, error)
+	GetByID(ctx context.Context, id string) (domain.Project, error)
+	GetByKey(ctx context.Context, key string) (domain.Project, error)
+	Create(ctx context.Context, project domain.Project) (domain.Project, error)
+	Update(ctx context.Context, id, name, description string) (domain.Project, error)
+	SetMember(ctx context.Context, id string, member domain.ProjectMember) (domain.Project, error)
+	RemoveMember(ctx context.Context, id, userID string) (domain.Project, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type projectRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewProjectRepository(client *mongo.Client, dbName, collectionName string, timeout time.Duration) ProjectRepository {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &projectRepository{collection: collection, timeout: timeout}
+}
+
+func (r *projectRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	return nil
+}
+
+func (r *projectRepository) GetAll(ctx context.Context, memberID string) ([]domain.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	filter := bson.M{}
+	if memberID != "" {
+		filter["members.user_id"] = memberID
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "key", Value: 1}}))
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	projects := []domain.Project{}
+	if err = cursor.All(ctx, &projects); err != nil {
+		return nil, domain.Internal(err)
+	}
+
+	return projects, nil
+}
+
+func (r *projectRepository) GetByID(ctx context.Context, id string) (domain.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Project{}, domain.InvalidID("project")
+	}
+
+	return r.findOne(ctx, bson.M{"_id": objectID})
+}
+
+func (r *projectRepository) GetByKey(ctx context.Context, key string) (domain.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.findOne(ctx, bson.M{"key": key})
+}
+
+func (r *projectRepository) findOne(ctx context.Context, filter bson.M) (domain.Project, error) {
+	var project domain.Project
+	err := r.collection.FindOne(ctx, filter).Decode(&project)
+	if err == mongo.ErrNoDocuments {
+		return domain.Project{}, domain.NotFound("project")
+	}
+	if err != nil {
+		return domain.Project{}, domain.Internal(err)
+	}
+
+	return project, nil
+}
+
+func (r *projectRepository) Create(ctx context.Context, project domain.Project) (domain.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	project.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, project)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.Project{}, errProjectKeyTaken(project.Key)
+	}
+	if err != nil {
+		return domain.Project{}, domain.Internal(err)
+	}
+
+	return project, nil
+}
+
+func (r *projectRepository) Update(ctx context.Context, id, name, description string) (domain.Project, error) {
+	return r.update(ctx, id, bson.M{}, bson.M{"$set": bson.M{"name": name, "description": description}})
+}
+
+// SetMember adds member to the project or changes the role of an existing
+// member.
+func (r *projectRepository) SetMember(ctx context.Context, id string, member domain.ProjectMember) (domain.Project, error) {
+	project, err := r.update(ctx, id, bson.M{"members.user_id": member.UserID}, bson.M{"$set": bson.M{"members.$.role": member.Role}})
+	if err == nil || !errors.Is(err, domain.ErrNotFound) {
+		return project, err
+	}
+	return r.update(ctx, id, bson.M{"members.user_id": bson.M{"$ne": member.UserID}}, bson.M{"$push": bson.M{"members": member}})
+}
+
+func (r *projectRepository) RemoveMember(ctx context.Context, id, userID string) (domain.Project, error) {
+	return r.update(ctx, id, bson.M{}, bson.M{"$pull": bson.M{"members": bson.M{"user_id": userID}}})
+}
+
+func (r *projectRepository) update(ctx context.Context, id string, filter, update bson.M) (domain.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Project{}, domain.InvalidID("project")
+	}
+	filter["_id"] = objectID
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var project domain.Project
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&project)
+	if err == mongo.ErrNoDocuments {
+		return domain.Project{}, domain.NotFound("project")
+	}
+	if err != nil {
+		return domain.Project{}, domain.Internal(err)
+	}
+
+	return project, nil
+}
+
+func (r *projectRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.InvalidID("project")
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.NotFound("project")
+	}
+
+	return nil
+}
+
+func errProjectKeyTaken(key string) error {
+	return domain.Conflict(fmt.Sprintf("project key %q is already taken", key))
+}
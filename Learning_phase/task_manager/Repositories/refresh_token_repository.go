@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RefreshTokenRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, token domain.RefreshToken) (domain.RefreshToken, error)
+	GetByHash(ctx context.Context, hash string) (domain.RefreshToken, error)
+	// Revoke reports false when the token was already revoked.
+	Revoke(ctx context.Context, id, reason string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID, reason string) error
+	RevokeAllForUser(ctx context.Context, userID, reason string) error
+}
+
+type refreshTokenRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewRefreshTokenRepository(client *mongo.Client, dbName, collectionName string, timeout time.Duration) RefreshTokenRepository {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &refreshTokenRepository{collection: collection, timeout: timeout}
+}
+
+func (r *refreshTokenRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "family_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token domain.RefreshToken) (domain.RefreshToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	token.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, token)
+	if err != nil {
+		return domain.RefreshToken{}, domain.Internal(err)
+	}
+
+	return token, nil
+}
+
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, hash string) (domain.RefreshToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var token domain.RefreshToken
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&token)
+	if err == mongo.ErrNoDocuments {
+		return domain.RefreshToken{}, domain.NotFound("refresh token")
+	}
+	if err != nil {
+		return domain.RefreshToken{}, domain.Internal(err)
+	}
+
+	return token, nil
+}
+
+func (r *refreshTokenRepository) Revoke(ctx context.Context, id, reason string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, domain.InvalidID("refresh token")
+	}
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": objectID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now(), "revoke_reason": reason}},
+	)
+	if err != nil {
+		return false, domain.Internal(err)
+	}
+
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID, reason string) error {
+	return r.revokeMany(ctx, bson.M{"family_id": familyID}, reason)
+}
+
+func (r *refreshTokenRepository) RevokeAllForUser(ctx context.Context, userID, reason string) error {
+	return r.revokeMany(ctx, bson.M{"user_id": userID}, reason)
+}
+
+func (r *refreshTokenRepository) revokeMany(ctx context.Context, filter bson.M, reason string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	filter["revoked_at"] = bson.M{"$exists": false}
+	_, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now(), "revoke_reason": reason}})
+	return domain.Internal(err)
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReminderRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Claim records reminder as sent. It reports false when the same
+	// reminder was recorded before, in which case it must not be sent again.
+	Claim(ctx context.Context, reminder domain.Reminder) (bool, error)
+	// Release forgets a claimed reminder whose delivery failed, so it is
+	// retried.
+	Release(ctx context.Context, reminder domain.Reminder) error
+	// Recorded lists the reminders already recorded for the tasks.
+	Recorded(ctx context.Context, taskIDs []string) ([]domain.Reminder, error)
+	DeleteByTasks(ctx context.Context, taskIDs []string) (int64, error)
+}
+
+type reminderRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewReminderRepository(client *mongo.Client, dbName, collectionName string, timeout time.Duration) ReminderRepository {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &reminderRepository{collection: collection, timeout: timeout}
+}
+
+// EnsureIndexes creates the unique index that makes Claim succeed only once
+// per reminder, across restarts and server instances.
+func (r *reminderRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "task_id", Value: 1},
+			{Key: "kind", Value: 1},
+			{Key: "window", Value: 1},
+			{Key: "due_date", Value: 1},
+		},
+		Options: options.Index().SetUnique(true).SetName("one_reminder_per_due_date"),
+	})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	return nil
+}
+
+func (r *reminderRepository) Claim(ctx context.Context, reminder domain.Reminder) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	reminder.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, reminder)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, domain.Internal(err)
+	}
+
+	return true, nil
+}
+
+func (r *reminderRepository) Release(ctx context.Context, reminder domain.Reminder) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, reminderKey(reminder))
+	if err != nil {
+		return domain.Internal(err)
+	}
+
+	return nil
+}
+
+func (r *reminderRepository) Recorded(ctx context.Context, taskIDs []string) ([]domain.Reminder, error) {
+	if len(taskIDs) == 0 {
+		return []domain.Reminder{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}})
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	reminders := []domain.Reminder{}
+	if err = cursor.All(ctx, &reminders); err != nil {
+		return nil, domain.Internal(err)
+	}
+	return reminders, nil
+}
+
+func (r *reminderRepository) DeleteByTasks(ctx context.Context, taskIDs []string) (int64, error) {
+	if len(taskIDs) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}})
+	if err != nil {
+		return 0, domain.Internal(err)
+	}
+
+	return result.DeletedCount, nil
+}
+
+func reminderKey(reminder domain.Reminder) bson.M {
+	return bson.M{"task_id": reminder.TaskID, "kind": reminder.Kind, "window": reminder.Window, "due_date": reminder.DueDate}
+}
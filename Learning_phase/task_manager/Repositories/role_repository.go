@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RoleRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	GetAll(ctx context.Context) ([]domain.Role, error)
+	GetByName(ctx context.Context, name string) (domain.Role, error)
+	Create(ctx context.Context, role domain.Role) (domain.Role, error)
+}
+
+type roleRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewRoleRepository(client *mongo.Client, dbName, collectionName string, timeout time.Duration) RoleRepository {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &roleRepository{collection: collection, timeout: timeout}
+}
+
+func (r *roleRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	return nil
+}
+
+func (r *roleRepository) GetAll(ctx context.Context) ([]domain.Role, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	var roles []domain.Role
+	if err = cursor.All(ctx, &roles); err != nil {
+		return nil, domain.Internal(err)
+	}
+
+	if roles == nil {
+		roles = []domain.Role{}
+	}
+	return roles, nil
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (domain.Role, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var role domain.Role
+	err := r.collection.FindOne(ctx, bson.M{"name": name}).Decode(&role)
+	if err == mongo.ErrNoDocuments {
+		return domain.Role{}, domain.NotFound("role")
+	}
+	if err != nil {
+		return domain.Role{}, domain.Internal(err)
+	}
+
+	return role, nil
+}
+
+func (r *roleRepository) Create(ctx context.Context, role domain.Role) (domain.Role, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	role.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, role)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.Role{}, errRoleExists(role.Name)
+	}
+	if err != nil {
+		return domain.Role{}, domain.Internal(err)
+	}
+
+	return role, nil
+}
+
+func errRoleExists(name string) error {
+	return domain.Conflict(fmt.Sprintf("role %q already exists", name))
+}
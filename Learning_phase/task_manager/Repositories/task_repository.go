@@ -2,45 +2,186 @@ package repositories
 
 import (
 	"context"
-	"errors"
+	"regexp"
 	"task_manager/Domain"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type TaskRepository interface {
-	GetAll() ([]domain.Task, error)
-	GetByID(id string) (domain.Task, error)
-	Create(task domain.Task) (domain.Task, error)
-	Update(id string, task domain.Task) (domain.Task, error)
-	Delete(id string) error
+	EnsureIndexes(ctx context.Context) error
+	GetAll(ctx context.Context, query domain.TaskQuery) ([]domain.Task, error)
+	GetByID(ctx context.Context, id string) (domain.Task, error)
+	Create(ctx context.Context, task domain.Task) (domain.Task, error)
+	Update(ctx context.Context, id string, task domain.Task, version int64) (domain.Task, error)
+	Patch(ctx context.Context, id string, patch domain.TaskPatch, version int64) (domain.Task, error)
+	Delete(ctx context.Context, id, deletedBy string, version int64) error
+	Restore(ctx context.Context, id string) (domain.Task, error)
+	Purge(ctx context.Context, deletedBefore time.Time) ([]string, error)
+	SetParent(ctx context.Context, id, parentID string, version int64) (domain.Task, error)
+	AddBlocker(ctx context.Context, id, blockerID string, version int64) (domain.Task, error)
+	RemoveBlocker(ctx context.Context, id, blockerID string, version int64) (domain.Task, error)
+	AddLabel(ctx context.Context, id, label string, version int64) (domain.Task, error)
+	RemoveLabel(ctx context.Context, id, label string, version int64) (domain.Task, error)
+	// RenameLabel and StripLabel apply to every task carrying the label,
+	// including trashed ones, and return how many tasks changed.
+	RenameLabel(ctx context.Context, oldName, newName string) (int64, error)
+	StripLabel(ctx context.Context, label string) (int64, error)
+	SetRecurrence(ctx context.Context, id string, recurrence *domain.Recurrence, version int64) (domain.Task, error)
+	// MarkNextOccurrence records whether the next occurrence of a recurring
+	// task has been created. It reports false when the flag already had that
+	// value, so setting it doubles as a claim on creating the occurrence.
+	MarkNextOccurrence(ctx context.Context, id string, created bool) (bool, error)
+	// GetPendingOccurrences lists recurring tasks that are done or overdue at
+	// now but whose next occurrence has not been created yet. Archived tasks
+	// are left out.
+	GetPendingOccurrences(ctx context.Context, now time.Time, limit int) ([]domain.Task, error)
 }
 
 type taskRepository struct {
 	collection *mongo.Collection
+	timeout    time.Duration
 }
 
-func NewTaskRepository(client *mongo.Client, dbName, collectionName string) TaskRepository {
+func NewTaskRepository(client *mongo.Client, dbName, collectionName string, timeout time.Duration) TaskRepository {
 	collection := client.Database(dbName).Collection(collectionName)
-	return &taskRepository{collection: collection}
+	return &taskRepository{collection: collection, timeout: timeout}
 }
 
-func (r *taskRepository) GetAll() ([]domain.Task, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// EnsureIndexes creates the multikey index behind label queries and the
+// index used to find the occurrences of a recurring series.
+func (r *taskRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "labels", Value: 1}}},
+		{Keys: bson.D{{Key: "blocked_by", Value: 1}}},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{Keys: bson.D{{Key: "recurrence.series_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+		{
+			Keys:    bson.D{{Key: "recurrence.next_created", Value: 1}, {Key: "due_date", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"recurrence.series_id": bson.M{"$exists": true}}),
+		},
+	})
 	if err != nil {
-		return nil, err
+		return domain.Internal(err)
+	}
+	return nil
+}
+
+var taskSortFields = map[string]string{
+	"":         "_id",
+	"id":       "_id",
+	"due_date": "due_date",
+	"title":    "title",
+	"status":   "status",
+}
+
+func (r *taskRepository) GetAll(ctx context.Context, query domain.TaskQuery) ([]domain.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	filter := bson.M{"deleted_at": nil}
+	if query.Deleted {
+		filter["deleted_at"] = bson.M{"$ne": nil}
+	}
+	var conditions bson.A
+	if query.OwnerID != "" {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"created_by": query.OwnerID},
+			bson.M{"assignee_id": query.OwnerID},
+		}})
+	}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+	if len(query.ExcludeStatuses) > 0 {
+		conditions = append(conditions, bson.M{"status": bson.M{"$nin": query.ExcludeStatuses}})
+	}
+	if query.Priority != "" {
+		filter["priority"] = query.Priority
+	}
+	dueDate := bson.M{}
+	if query.DueAfter != nil {
+		dueDate["$gte"] = *query.DueAfter
+	}
+	if query.DueBefore != nil {
+		dueDate["$lte"] = *query.DueBefore
+	}
+	if len(dueDate) > 0 {
+		filter["due_date"] = dueDate
+	}
+	if query.ProjectID != "" {
+		filter["project_id"] = query.ProjectID
+	} else if query.NoProject {
+		filter["project_id"] = bson.M{"$in": bson.A{nil, ""}}
+	}
+	if query.ParentID != "" {
+		filter["parent_id"] = query.ParentID
+	}
+	if query.BlockedBy != "" {
+		filter["blocked_by"] = query.BlockedBy
+	}
+	if query.SeriesID != "" {
+		filter["recurrence.series_id"] = query.SeriesID
+	}
+	if len(query.Labels) > 0 {
+		op := "$in"
+		if query.LabelMatch == domain.LabelMatchAll {
+			op = "$all"
+		}
+		filter["labels"] = bson.M{op: query.Labels}
+	}
+	if query.Title != "" {
+		filter["title"] = bson.M{"$regex": regexp.QuoteMeta(query.Title), "$options": "i"}
+	}
+
+	sortField, ok := taskSortFields[query.SortBy]
+	if !ok {
+		sortField = "_id"
+	}
+	direction, op := 1, "$gt"
+	if query.SortDesc {
+		direction, op = -1, "$lt"
+	}
+
+	if query.Cursor != nil {
+		if sortField == "_id" {
+			filter["_id"] = bson.M{op: query.Cursor.ID}
+		} else {
+			conditions = append(conditions, bson.M{"$or": bson.A{
+				bson.M{sortField: bson.M{op: query.Cursor.Value}},
+				bson.M{sortField: query.Cursor.Value, "_id": bson.M{op: query.Cursor.ID}},
+			}})
+		}
+	}
+	if len(conditions) > 0 {
+		filter["$and"] = conditions
+	}
+
+	sort := bson.D{{Key: "_id", Value: direction}}
+	if sortField != "_id" {
+		sort = append(bson.D{{Key: sortField, Value: direction}}, sort...)
+	}
+	opts := options.Find().SetSort(sort)
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, domain.Internal(err)
 	}
 	defer cursor.Close(ctx)
 
 	var tasks []domain.Task
 	if err = cursor.All(ctx, &tasks); err != nil {
-		return nil, err
+		return nil, domain.Internal(err)
 	}
 
 	if tasks == nil {
@@ -49,86 +190,375 @@ func (r *taskRepository) GetAll() ([]domain.Task, error) {
 	return tasks, nil
 }
 
-func (r *taskRepository) GetByID(id string) (domain.Task, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *taskRepository) GetByID(ctx context.Context, id string) (domain.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return domain.Task{}, errors.New("invalid task ID")
+		return domain.Task{}, domain.InvalidID("task")
 	}
 
 	var task domain.Task
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&task)
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID, "deleted_at": nil}).Decode(&task)
 	if err == mongo.ErrNoDocuments {
-		return domain.Task{}, errors.New("task not found")
+		return domain.Task{}, domain.NotFound("task")
 	}
 	if err != nil {
-		return domain.Task{}, err
+		return domain.Task{}, domain.Internal(err)
 	}
 
 	return task, nil
 }
 
-func (r *taskRepository) Create(task domain.Task) (domain.Task, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *taskRepository) Create(ctx context.Context, task domain.Task) (domain.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
 	task.ID = primitive.NewObjectID()
+	task.Version = 1
 	_, err := r.collection.InsertOne(ctx, task)
 	if err != nil {
-		return domain.Task{}, err
+		return domain.Task{}, domain.Internal(err)
 	}
 
 	return task, nil
 }
 
-func (r *taskRepository) Update(id string, updatedTask domain.Task) (domain.Task, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *taskRepository) Update(ctx context.Context, id string, updatedTask domain.Task, version int64) (domain.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return domain.Task{}, errors.New("invalid task ID")
+		return domain.Task{}, domain.InvalidID("task")
 	}
 
-	updatedTask.ID = objectID
 	update := bson.M{
 		"$set": bson.M{
-			"title":       updatedTask.Title,
-			"description": updatedTask.Description,
-			"due_date":    updatedTask.DueDate,
-			"status":      updatedTask.Status,
+			"title":            updatedTask.Title,
+			"description":      updatedTask.Description,
+			"due_date":         updatedTask.DueDate,
+			"status":           updatedTask.Status,
+			"status_history":   updatedTask.StatusHistory,
+			"assignee_id":      updatedTask.AssigneeID,
+			"priority":         updatedTask.Priority,
+			"estimate_minutes": updatedTask.EstimateMinutes,
 		},
+		"$inc": bson.M{"version": 1},
 	}
 
-	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	return r.findAndUpdate(ctx, objectID, version, update)
+}
+
+func (r *taskRepository) Patch(ctx context.Context, id string, patch domain.TaskPatch, version int64) (domain.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return domain.Task{}, err
+		return domain.Task{}, domain.InvalidID("task")
 	}
-	if result.MatchedCount == 0 {
-		return domain.Task{}, errors.New("task not found")
+
+	set := bson.M{}
+	if patch.Title != nil {
+		set["title"] = *patch.Title
 	}
+	if patch.Description != nil {
+		set["description"] = *patch.Description
+	}
+	if patch.DueDate != nil {
+		set["due_date"] = *patch.DueDate
+	}
+	if patch.Status != nil {
+		set["status"] = *patch.Status
+		set["status_history"] = patch.StatusHistory
+	}
+	if patch.AssigneeID != nil {
+		set["assignee_id"] = *patch.AssigneeID
+	}
+	if patch.Priority != nil {
+		set["priority"] = *patch.Priority
+	}
+	if patch.EstimateMinutes != nil {
+		set["estimate_minutes"] = *patch.EstimateMinutes
+	}
+	if patch.Recurrence != nil {
+		set["recurrence"] = patch.Recurrence
+	}
+	if len(set) == 0 {
+		return r.GetByID(ctx, id)
+	}
+
+	return r.findAndUpdate(ctx, objectID, version, bson.M{"$set": set, "$inc": bson.M{"version": 1}})
+}
+
+func (r *taskRepository) SetParent(ctx context.Context, id, parentID string, version int64) (domain.Task, error) {
+	update := bson.M{"$set": bson.M{"parent_id": parentID}}
+	if parentID == "" {
+		update = bson.M{"$unset": bson.M{"parent_id": ""}}
+	}
+	return r.updateEdges(ctx, id, update, version)
+}
+
+func (r *taskRepository) AddBlocker(ctx context.Context, id, blockerID string, version int64) (domain.Task, error) {
+	return r.updateEdges(ctx, id, bson.M{"$addToSet": bson.M{"blocked_by": blockerID}}, version)
+}
+
+func (r *taskRepository) RemoveBlocker(ctx context.Context, id, blockerID string, version int64) (domain.Task, error) {
+	return r.updateEdges(ctx, id, bson.M{"$pull": bson.M{"blocked_by": blockerID}}, version)
+}
+
+func (r *taskRepository) AddLabel(ctx context.Context, id, label string, version int64) (domain.Task, error) {
+	return r.updateEdges(ctx, id, bson.M{"$addToSet": bson.M{"labels": label}}, version)
+}
+
+func (r *taskRepository) RemoveLabel(ctx context.Context, id, label string, version int64) (domain.Task, error) {
+	return r.updateEdges(ctx, id, bson.M{"$pull": bson.M{"labels": label}}, version)
+}
 
-	return updatedTask, nil
+// RenameLabel replaces oldName in place, or drops it when the task already
+// carries newName, so a task never ends up with the label twice.
+func (r *taskRepository) RenameLabel(ctx context.Context, oldName, newName string) (int64, error) {
+	labels := bson.M{"$cond": bson.A{
+		bson.M{"$in": bson.A{bson.M{"$literal": newName}, "$labels"}},
+		bson.M{"$filter": bson.M{"input": "$labels", "cond": bson.M{"$ne": bson.A{"$$this", bson.M{"$literal": oldName}}}}},
+		bson.M{"$map": bson.M{"input": "$labels", "in": bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{"$$this", bson.M{"$literal": oldName}}}, bson.M{"$literal": newName}, "$$this",
+		}}}},
+	}}
+	return r.updateLabelled(ctx, oldName, mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"labels":  labels,
+		"version": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}},
+	}}}})
 }
 
-func (r *taskRepository) Delete(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *taskRepository) StripLabel(ctx context.Context, label string) (int64, error) {
+	return r.updateLabelled(ctx, label, bson.M{"$pull": bson.M{"labels": label}, "$inc": bson.M{"version": 1}})
+}
+
+func (r *taskRepository) SetRecurrence(ctx context.Context, id string, recurrence *domain.Recurrence, version int64) (domain.Task, error) {
+	update := bson.M{"$set": bson.M{"recurrence": recurrence}}
+	if recurrence == nil {
+		update = bson.M{"$unset": bson.M{"recurrence": ""}}
+	}
+	return r.updateEdges(ctx, id, update, version)
+}
+
+// MarkNextOccurrence leaves the version alone: the flag is bookkeeping for
+// the scheduler and not part of the task clients see.
+func (r *taskRepository) MarkNextOccurrence(ctx context.Context, id string, created bool) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid task ID")
+		return false, domain.InvalidID("task")
 	}
 
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	filter := bson.M{"_id": objectID, "recurrence": bson.M{"$ne": nil}, "recurrence.next_created": bson.M{"$ne": created}}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"recurrence.next_created": created}})
 	if err != nil {
-		return err
+		return false, domain.Internal(err)
 	}
-	if result.DeletedCount == 0 {
-		return errors.New("task not found")
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *taskRepository) GetPendingOccurrences(ctx context.Context, now time.Time, limit int) ([]domain.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	filter := bson.M{
+		"deleted_at":              nil,
+		"recurrence.series_id":    bson.M{"$exists": true},
+		"recurrence.next_created": false,
+		"status":                  bson.M{"$ne": domain.StatusArchived},
+		"$or": bson.A{
+			bson.M{"status": domain.StatusDone},
+			bson.M{"due_date": bson.M{"$lt": now}},
+		},
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	tasks := []domain.Task{}
+	if err = cursor.All(ctx, &tasks); err != nil {
+		return nil, domain.Internal(err)
+	}
+	return tasks, nil
+}
+
+// updateLabelled applies update, which must also bump the version, to
+// every task carrying label.
+func (r *taskRepository) updateLabelled(ctx context.Context, label string, update interface{}) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.collection.UpdateMany(ctx, bson.M{"labels": label}, update)
+	if err != nil {
+		return 0, domain.Internal(err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *taskRepository) updateEdges(ctx context.Context, id string, update bson.M, version int64) (domain.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Task{}, domain.InvalidID("task")
+	}
+
+	update["$inc"] = bson.M{"version": 1}
+	return r.findAndUpdate(ctx, objectID, version, update)
+}
+
+func (r *taskRepository) findAndUpdate(ctx context.Context, objectID primitive.ObjectID, version int64, update bson.M) (domain.Task, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var task domain.Task
+	err := r.collection.FindOneAndUpdate(ctx, versionFilter(objectID, version), update, opts).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return domain.Task{}, r.missingTaskError(ctx, objectID, version)
+	}
+	if err != nil {
+		return domain.Task{}, domain.Internal(err)
+	}
+
+	return task, nil
+}
+
+func versionFilter(objectID primitive.ObjectID, version int64) bson.M {
+	filter := bson.M{"_id": objectID, "deleted_at": nil}
+	switch {
+	case version == 0:
+		// Legacy tasks have no version field and are served as version 0.
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	case version != domain.AnyVersion:
+		filter["version"] = version
+	}
+	return filter
+}
+
+func (r *taskRepository) missingTaskError(ctx context.Context, objectID primitive.ObjectID, version int64) error {
+	if version != domain.AnyVersion {
+		count, err := r.collection.CountDocuments(ctx, bson.M{"_id": objectID, "deleted_at": nil})
+		if err != nil {
+			return domain.Internal(err)
+		}
+		if count > 0 {
+			return domain.ErrVersionMismatch
+		}
+	}
+	return domain.NotFound("task")
+}
+
+func (r *taskRepository) Delete(ctx context.Context, id, deletedBy string, version int64) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.InvalidID("task")
+	}
+
+	update := bson.M{
+		"$set": bson.M{"deleted_at": time.Now().UTC(), "deleted_by": deletedBy},
+		"$inc": bson.M{"version": 1},
+	}
+	result, err := r.collection.UpdateOne(ctx, versionFilter(objectID, version), update)
+	if err != nil {
+		return domain.Internal(err)
+	}
+	if result.MatchedCount == 0 {
+		return r.missingTaskError(ctx, objectID, version)
 	}
 
 	return nil
 }
+
+func (r *taskRepository) Restore(ctx context.Context, id string) (domain.Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.Task{}, domain.InvalidID("task")
+	}
+
+	update := bson.M{
+		"$unset": bson.M{"deleted_at": "", "deleted_by": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var task domain.Task
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID, "deleted_at": bson.M{"$ne": nil}}, update, opts).Decode(&task)
+	if err == mongo.ErrNoDocuments {
+		return domain.Task{}, domain.NotFound("task")
+	}
+	if err != nil {
+		return domain.Task{}, domain.Internal(err)
+	}
+
+	return task, nil
+}
+
+// Purge permanently removes tasks trashed before deletedBefore and returns
+// their IDs. Tasks restored while the purge runs are left alone.
+func (r *taskRepository) Purge(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	candidates, err := r.findIDs(ctx, bson.M{"deleted_at": bson.M{"$lt": deletedBefore}})
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+
+	_, err = r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": candidates}, "deleted_at": bson.M{"$lt": deletedBefore}})
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	remaining, err := r.findIDs(ctx, bson.M{"_id": bson.M{"$in": candidates}})
+	if err != nil {
+		return nil, err
+	}
+
+	kept := make(map[primitive.ObjectID]bool, len(remaining))
+	for _, id := range remaining {
+		kept[id] = true
+	}
+	var purged []string
+	for _, id := range candidates {
+		if !kept[id] {
+			purged = append(purged, id.Hex())
+		}
+	}
+
+	return purged, nil
+}
+
+func (r *taskRepository) findIDs(ctx context.Context, filter bson.M) ([]primitive.ObjectID, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, domain.Internal(err)
+	}
+
+	ids := make([]primitive.ObjectID, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	return ids, nil
+}
@@ -0,0 +1,255 @@
+package repositories
+
+import (
+	"context"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TimeLogRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	GetByTask(ctx context.Context, taskID string) ([]domain.TimeLog, error)
+	GetByID(ctx context.Context, id string) (domain.TimeLog, error)
+	GetRunning(ctx context.Context, userID string) (domain.TimeLog, error)
+	// Create stores a time log. Creating a running timer for a user who
+	// already has one fails with domain.ErrTimerRunning.
+	Create(ctx context.Context, log domain.TimeLog) (domain.TimeLog, error)
+	Stop(ctx context.Context, id string, end time.Time, durationSeconds int64, note string) (domain.TimeLog, error)
+	Delete(ctx context.Context, id string) error
+	DeleteByTasks(ctx context.Context, taskIDs []string) (int64, error)
+	// TotalsByUser and TotalsByTask sum the finished time logs matching
+	// query, largest total first.
+	TotalsByUser(ctx context.Context, query domain.TimeLogQuery) ([]domain.TimeTotal, error)
+	TotalsByTask(ctx context.Context, query domain.TimeLogQuery) ([]domain.TimeTotal, error)
+}
+
+type timeLogRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewTimeLogRepository(client *mongo.Client, dbName, collectionName string, timeout time.Duration) TimeLogRepository {
+	collection := client.Database(dbName).Collection(collectionName)
+	return &timeLogRepository{collection: collection, timeout: timeout}
+}
+
+// EnsureIndexes creates the index on task_id and a unique partial index
+// that allows each user only one running timer.
+func (r *timeLogRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "task_id", Value: 1}, {Key: "start", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "start", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("one_running_timer_per_user").SetUnique(true).SetPartialFilterExpression(bson.M{"running": true}),
+		},
+	})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	return nil
+}
+
+func (r *timeLogRepository) GetByTask(ctx context.Context, taskID string) ([]domain.TimeLog, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "start", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"task_id": taskID}, opts)
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	logs := []domain.TimeLog{}
+	if err = cursor.All(ctx, &logs); err != nil {
+		return nil, domain.Internal(err)
+	}
+
+	return logs, nil
+}
+
+func (r *timeLogRepository) GetByID(ctx context.Context, id string) (domain.TimeLog, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.TimeLog{}, domain.InvalidID("time log")
+	}
+
+	return r.findOne(ctx, bson.M{"_id": objectID}, "time log")
+}
+
+func (r *timeLogRepository) GetRunning(ctx context.Context, userID string) (domain.TimeLog, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.findOne(ctx, bson.M{"user_id": userID, "running": true}, "running timer")
+}
+
+func (r *timeLogRepository) findOne(ctx context.Context, filter bson.M, resource string) (domain.TimeLog, error) {
+	var log domain.TimeLog
+	err := r.collection.FindOne(ctx, filter).Decode(&log)
+	if err == mongo.ErrNoDocuments {
+		return domain.TimeLog{}, domain.NotFound(resource)
+	}
+	if err != nil {
+		return domain.TimeLog{}, domain.Internal(err)
+	}
+
+	return log, nil
+}
+
+func (r *timeLogRepository) Create(ctx context.Context, log domain.TimeLog) (domain.TimeLog, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	log.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, log)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.TimeLog{}, domain.ErrTimerRunning
+	}
+	if err != nil {
+		return domain.TimeLog{}, domain.Internal(err)
+	}
+
+	return log, nil
+}
+
+func (r *timeLogRepository) Stop(ctx context.Context, id string, end time.Time, durationSeconds int64, note string) (domain.TimeLog, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.TimeLog{}, domain.InvalidID("time log")
+	}
+
+	update := bson.M{"$set": bson.M{"running": false, "end": end, "duration_seconds": durationSeconds, "note": note}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var log domain.TimeLog
+	err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectID, "running": true}, update, opts).Decode(&log)
+	if err == mongo.ErrNoDocuments {
+		return domain.TimeLog{}, domain.NotFound("running timer")
+	}
+	if err != nil {
+		return domain.TimeLog{}, domain.Internal(err)
+	}
+
+	return log, nil
+}
+
+func (r *timeLogRepository) Delete(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.InvalidID("time log")
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	if result.DeletedCount == 0 {
+		return domain.NotFound("time log")
+	}
+
+	return nil
+}
+
+func (r *timeLogRepository) DeleteByTasks(ctx context.Context, taskIDs []string) (int64, error) {
+	if len(taskIDs) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"task_id": bson.M{"$in": taskIDs}})
+	if err != nil {
+		return 0, domain.Internal(err)
+	}
+
+	return result.DeletedCount, nil
+}
+
+func (r *timeLogRepository) TotalsByUser(ctx context.Context, query domain.TimeLogQuery) ([]domain.TimeTotal, error) {
+	return r.totals(ctx, query, "user_id")
+}
+
+func (r *timeLogRepository) TotalsByTask(ctx context.Context, query domain.TimeLogQuery) ([]domain.TimeTotal, error) {
+	return r.totals(ctx, query, "task_id")
+}
+
+func (r *timeLogRepository) totals(ctx context.Context, query domain.TimeLogQuery, groupBy string) ([]domain.TimeTotal, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	match := bson.M{"running": false}
+	if query.TaskID != "" {
+		match["task_id"] = query.TaskID
+	}
+	if query.UserID != "" {
+		match["user_id"] = query.UserID
+	}
+	start := bson.M{}
+	if query.From != nil {
+		start["$gte"] = *query.From
+	}
+	if query.To != nil {
+		start["$lte"] = *query.To
+	}
+	if len(start) > 0 {
+		match["start"] = start
+	}
+
+	// Sorting before grouping makes each user's total carry the username of
+	// their latest entry.
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.D{{Key: "start", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$" + groupBy},
+			{Key: "username", Value: bson.M{"$first": "$username"}},
+			{Key: "total_seconds", Value: bson.M{"$sum": "$duration_seconds"}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "total_seconds", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		Key          string `bson:"_id"`
+		Username     string `bson:"username"`
+		TotalSeconds int64  `bson:"total_seconds"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, domain.Internal(err)
+	}
+
+	totals := make([]domain.TimeTotal, len(groups))
+	for i, g := range groups {
+		totals[i] = domain.TimeTotal{TotalSeconds: g.TotalSeconds}
+		if groupBy == "user_id" {
+			totals[i].UserID, totals[i].Username = g.Key, g.Username
+		} else {
+			totals[i].TaskID = g.Key
+		}
+	}
+	return totals, nil
+}
@@ -2,83 +2,228 @@ package repositories
 
 import (
 	"context"
-	"errors"
 	"task_manager/Domain"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserRepository interface {
-	Create(user domain.User) (domain.User, error)
-	GetByUsername(username string) (domain.User, error)
-	CountUsers() (int64, error)
-	PromoteToAdmin(username string) error
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, user domain.User) (domain.User, error)
+	GetByID(ctx context.Context, id string) (domain.User, error)
+	GetByUsername(ctx context.Context, username string) (domain.User, error)
+	GetByUsernames(ctx context.Context, usernames []string) ([]domain.User, error)
+	CountUsers(ctx context.Context) (int64, error)
+	CountByRole(ctx context.Context, role string) (int64, error)
+	SetRole(ctx context.Context, username, role string) error
+	RecordLoginFailure(ctx context.Context, username string, at, windowStart time.Time) (int, error)
+	LockUntil(ctx context.Context, username string, until time.Time) error
+	ResetLoginFailures(ctx context.Context, username string) error
 }
 
 type userRepository struct {
 	collection *mongo.Collection
+	timeout    time.Duration
 }
 
-func NewUserRepository(client *mongo.Client, dbName, collectionName string) UserRepository {
+func NewUserRepository(client *mongo.Client, dbName, collectionName string, timeout time.Duration) UserRepository {
 	collection := client.Database(dbName).Collection(collectionName)
-	return &userRepository{collection: collection}
+	return &userRepository{collection: collection, timeout: timeout}
 }
 
-func (r *userRepository) Create(user domain.User) (domain.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *userRepository) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "username", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	return nil
+}
+
+func (r *userRepository) Create(ctx context.Context, user domain.User) (domain.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
 	user.ID = primitive.NewObjectID()
 	_, err := r.collection.InsertOne(ctx, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.User{}, errUsernameTaken()
+	}
 	if err != nil {
-		return domain.User{}, err
+		return domain.User{}, domain.Internal(err)
 	}
 
 	return user, nil
 }
 
-func (r *userRepository) GetByUsername(username string) (domain.User, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *userRepository) GetByID(ctx context.Context, id string) (domain.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.User{}, domain.InvalidID("user")
+	}
+
+	var user domain.User
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return domain.User{}, domain.NotFound("user")
+	}
+	if err != nil {
+		return domain.User{}, domain.Internal(err)
+	}
+
+	return user, nil
+}
+
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (domain.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
 	var user domain.User
 	err := r.collection.FindOne(ctx, bson.M{"username": username}).Decode(&user)
 	if err == mongo.ErrNoDocuments {
-		return domain.User{}, errors.New("user not found")
+		return domain.User{}, domain.NotFound("user")
 	}
 	if err != nil {
-		return domain.User{}, err
+		return domain.User{}, domain.Internal(err)
 	}
 
 	return user, nil
 }
 
-func (r *userRepository) CountUsers() (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// GetByUsernames returns the users among usernames that exist, in no
+// particular order. Only IDs and usernames are loaded.
+func (r *userRepository) GetByUsernames(ctx context.Context, usernames []string) ([]domain.User, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "username": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"username": bson.M{"$in": usernames}}, opts)
+	if err != nil {
+		return nil, domain.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	var users []domain.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, domain.Internal(err)
+	}
+	return users, nil
+}
+
+func (r *userRepository) CountUsers(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
 	count, err := r.collection.CountDocuments(ctx, bson.M{})
-	return count, err
+	return count, domain.Internal(err)
 }
 
-func (r *userRepository) PromoteToAdmin(username string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *userRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"role": role})
+	return count, domain.Internal(err)
+}
+
+func (r *userRepository) SetRole(ctx context.Context, username, role string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
 	defer cancel()
 
 	result, err := r.collection.UpdateOne(
 		ctx,
 		bson.M{"username": username},
-		bson.M{"$set": bson.M{"role": "admin"}},
+		bson.M{"$set": bson.M{"role": role}},
 	)
 	if err != nil {
-		return err
+		return domain.Internal(err)
 	}
 	if result.MatchedCount == 0 {
-		return errors.New("user not found")
+		return domain.NotFound("user")
 	}
 
 	return nil
 }
+
+// RecordLoginFailure counts a failed login and returns the number of
+// failures since windowStart, restarting the count if the previous failure
+// is older than that.
+func (r *userRepository) RecordLoginFailure(ctx context.Context, username string, at, windowStart time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"failed_logins": bson.M{"$cond": bson.A{
+			bson.M{"$lt": bson.A{"$last_failed_login", windowStart}},
+			1,
+			bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failed_logins", 0}}, 1}},
+		}},
+		"last_failed_login": at,
+	}}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var user domain.User
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"username": username}, update, opts).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return 0, domain.NotFound("user")
+	}
+	if err != nil {
+		return 0, domain.Internal(err)
+	}
+
+	return user.FailedLogins, nil
+}
+
+func (r *userRepository) LockUntil(ctx context.Context, username string, until time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"username": username}, bson.M{"$set": bson.M{"locked_until": until}})
+	if err != nil {
+		return domain.Internal(err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.NotFound("user")
+	}
+
+	return nil
+}
+
+func (r *userRepository) ResetLoginFailures(ctx context.Context, username string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"username": username},
+		bson.M{"$unset": bson.M{"failed_logins": "", "last_failed_login": "", "locked_until": ""}},
+	)
+	if err != nil {
+		return domain.Internal(err)
+	}
+	if result.MatchedCount == 0 {
+		return domain.NotFound("user")
+	}
+
+	return nil
+}
+
+func errUsernameTaken() error {
+	return domain.Conflict("username already exists")
+}
@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"sort"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"time"
+)
+
+var ErrInvalidAuditQuery = domain.NewError(domain.ErrValidation, "invalid audit query")
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type AuditUsecase interface {
+	Record(ctx context.Context, actor domain.Actor, action, targetType, targetID string, before, after interface{})
+	GetEntries(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEntry, error)
+}
+
+type auditUsecase struct {
+	auditRepo repositories.AuditRepository
+}
+
+func NewAuditUsecase(auditRepo repositories.AuditRepository) AuditUsecase {
+	return &auditUsecase{auditRepo: auditRepo}
+}
+
+// Record appends an audit entry for a mutation that has already been applied.
+// Failures are logged rather than returned so a broken audit store cannot
+// make a successful write look like it failed, and the write is detached from
+// request cancellation so a client hanging up does not lose the entry.
+func (u *auditUsecase) Record(ctx context.Context, actor domain.Actor, action, targetType, targetID string, before, after interface{}) {
+	changes, err := diffFields(before, after)
+	if err != nil {
+		log.Printf("audit: diffing %s %s for %s: %v", targetType, targetID, action, err)
+	}
+
+	_, err = u.auditRepo.Append(context.WithoutCancel(ctx), domain.AuditEntry{
+		ActorID:       actor.UserID,
+		ActorUsername: actor.Username,
+		Action:        action,
+		TargetType:    targetType,
+		TargetID:      targetID,
+		Changes:       changes,
+		Timestamp:     time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("audit: recording %s on %s %s: %v", action, targetType, targetID, err)
+	}
+}
+
+func (u *auditUsecase) GetEntries(ctx context.Context, query domain.AuditQuery) ([]domain.AuditEntry, error) {
+	if query.Limit < 0 || query.Limit > maxAuditLimit {
+		return nil, ErrInvalidAuditQuery
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultAuditLimit
+	}
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		return nil, ErrInvalidAuditQuery
+	}
+	return u.auditRepo.Find(ctx, query)
+}
+
+// diffFields compares the JSON representations of before and after and
+// returns the top-level fields that differ. A nil side stands for a target
+// that did not exist yet or no longer exists.
+func diffFields(before, after interface{}) ([]domain.FieldChange, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(beforeFields)+len(afterFields))
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []domain.FieldChange{}
+	for _, name := range names {
+		if !reflect.DeepEqual(beforeFields[name], afterFields[name]) {
+			changes = append(changes, domain.FieldChange{Field: name, Before: beforeFields[name], After: afterFields[name]})
+		}
+	}
+	return changes, nil
+}
+
+func jsonFields(value interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if value == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
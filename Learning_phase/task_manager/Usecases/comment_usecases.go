@@ -0,0 +1,200 @@
+package usecases
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"time"
+)
+
+var ErrCommentForbidden = domain.NewError(domain.ErrForbidden, "only the author can change this comment")
+
+// mentionPattern finds @username tokens that are not part of a word, so
+// e-mail addresses are not taken for mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9._@-])@([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// maxMentions caps how many distinct @tokens of a comment are looked up.
+// Later tokens stay plain text.
+const maxMentions = 20
+
+type CommentUsecase interface {
+	GetComments(ctx context.Context, actor domain.Actor, taskID string) ([]domain.Comment, error)
+	AddComment(ctx context.Context, actor domain.Actor, taskID, body string) (domain.Comment, error)
+	EditComment(ctx context.Context, actor domain.Actor, taskID, commentID, body string) (domain.Comment, error)
+	DeleteComment(ctx context.Context, actor domain.Actor, taskID, commentID string) error
+}
+
+type commentUsecase struct {
+	commentRepo  repositories.CommentRepository
+	taskRepo     repositories.TaskRepository
+	userRepo     repositories.UserRepository
+	projectRepo  repositories.ProjectRepository
+	auditUsecase AuditUsecase
+}
+
+func NewCommentUsecase(commentRepo repositories.CommentRepository, taskRepo repositories.TaskRepository, userRepo repositories.UserRepository, projectRepo repositories.ProjectRepository, auditUsecase AuditUsecase) CommentUsecase {
+	return &commentUsecase{commentRepo: commentRepo, taskRepo: taskRepo, userRepo: userRepo, projectRepo: projectRepo, auditUsecase: auditUsecase}
+}
+
+func (u *commentUsecase) GetComments(ctx context.Context, actor domain.Actor, taskID string) ([]domain.Comment, error) {
+	if err := u.checkTask(ctx, actor, taskID); err != nil {
+		return nil, err
+	}
+	return u.commentRepo.GetByTask(ctx, taskID)
+}
+
+func (u *commentUsecase) AddComment(ctx context.Context, actor domain.Actor, taskID, body string) (domain.Comment, error) {
+	if err := u.checkTask(ctx, actor, taskID); err != nil {
+		return domain.Comment{}, err
+	}
+
+	body = strings.TrimSpace(body)
+	if err := validateComment(body).Err(); err != nil {
+		return domain.Comment{}, err
+	}
+	mentions, err := u.resolveMentions(ctx, body)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	created, err := u.commentRepo.Create(ctx, domain.Comment{
+		TaskID:         taskID,
+		AuthorID:       actor.UserID,
+		AuthorUsername: actor.Username,
+		Body:           body,
+		Mentions:       mentions,
+		CreatedAt:      time.Now().UTC(),
+	})
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditCommentCreate, domain.AuditTargetComment, created.ID.Hex(), nil, created)
+	return created, nil
+}
+
+func (u *commentUsecase) EditComment(ctx context.Context, actor domain.Actor, taskID, commentID, body string) (domain.Comment, error) {
+	existing, err := u.loadComment(ctx, actor, taskID, commentID)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	body = strings.TrimSpace(body)
+	if err := validateComment(body).Err(); err != nil {
+		return domain.Comment{}, err
+	}
+	mentions, err := u.resolveMentions(ctx, body)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	updated, err := u.commentRepo.Update(ctx, commentID, body, mentions, time.Now().UTC())
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditCommentUpdate, domain.AuditTargetComment, commentID, existing, updated)
+	return updated, nil
+}
+
+func (u *commentUsecase) DeleteComment(ctx context.Context, actor domain.Actor, taskID, commentID string) error {
+	existing, err := u.loadComment(ctx, actor, taskID, commentID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.commentRepo.Delete(ctx, commentID); err != nil {
+		return err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditCommentDelete, domain.AuditTargetComment, commentID, existing, nil)
+	return nil
+}
+
+// checkTask makes sure the task exists, is not in the trash and is visible
+// to actor, judged by their project role for project tasks. Comments are
+// only ever reached through their task, so those of a deleted task disappear
+// with it and come back if it is restored.
+func (u *commentUsecase) checkTask(ctx context.Context, actor domain.Actor, taskID string) error {
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if actor, err = actorInProject(ctx, u.projectRepo, actor, task.ProjectID, "task"); err != nil {
+		return err
+	}
+	if !isPermitted(actor, task, domain.PermTasksReadAny, domain.PermTasksReadOwn) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (u *commentUsecase) loadComment(ctx context.Context, actor domain.Actor, taskID, commentID string) (domain.Comment, error) {
+	if err := u.checkTask(ctx, actor, taskID); err != nil {
+		return domain.Comment{}, err
+	}
+
+	comment, err := u.commentRepo.GetByID(ctx, commentID)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	if comment.TaskID != taskID {
+		return domain.Comment{}, domain.NotFound("comment")
+	}
+	if comment.AuthorID != actor.UserID && !actor.Has(domain.PermCommentsManage) {
+		return domain.Comment{}, ErrCommentForbidden
+	}
+	return comment, nil
+}
+
+// resolveMentions looks up the first maxMentions distinct @usernames in
+// body with a single query. Names that do not belong to a user are left as
+// plain text. A trailing '.', '_' or '-' is tried both with and without, so
+// "@alice." at the end of a sentence works.
+func (u *commentUsecase) resolveMentions(ctx context.Context, body string) ([]domain.Mention, error) {
+	var tokens, names []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		token := match[1]
+		if seen[token] {
+			continue
+		}
+		if len(tokens) == maxMentions {
+			break
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+		names = append(names, token)
+		if trimmed := strings.TrimRight(token, "._-"); trimmed != token && trimmed != "" {
+			names = append(names, trimmed)
+		}
+	}
+
+	mentions := []domain.Mention{}
+	if len(tokens) == 0 {
+		return mentions, nil
+	}
+	users, err := u.userRepo.GetByUsernames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]domain.User, len(users))
+	for _, user := range users {
+		byName[user.Username] = user
+	}
+
+	mentioned := make(map[string]bool)
+	for _, token := range tokens {
+		for _, name := range []string{token, strings.TrimRight(token, "._-")} {
+			user, ok := byName[name]
+			if !ok {
+				continue
+			}
+			if !mentioned[name] {
+				mentioned[name] = true
+				mentions = append(mentions, domain.Mention{UserID: user.ID.Hex(), Username: user.Username})
+			}
+			break
+		}
+	}
+	return mentions, nil
+}
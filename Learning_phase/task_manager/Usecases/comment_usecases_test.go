@@ -0,0 +1,73 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"testing"
+)
+
+// countingUserRepository counts the lookups made against the users.
+type countingUserRepository struct {
+	repositories.UserRepository
+	lookups int
+}
+
+func (r *countingUserRepository) GetByUsernames(ctx context.Context, usernames []string) ([]domain.User, error) {
+	r.lookups++
+	return r.UserRepository.GetByUsernames(ctx, usernames)
+}
+
+func TestResolveMentions(t *testing.T) {
+	users := repositories.NewMemoryUserRepository()
+	for _, name := range []string{"alice", "bob", "carol.", "u0", "u25"} {
+		if _, err := users.Create(context.Background(), domain.User{Username: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var many []string
+	for i := 0; i < 30; i++ {
+		many = append(many, fmt.Sprintf("@u%d", i))
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		lookups int
+	}{
+		{name: "no mentions", body: "plain text, mail me at alice@example.com", want: nil, lookups: 0},
+		{name: "known and unknown", body: "@alice and @nobody, ask @bob", want: []string{"alice", "bob"}, lookups: 1},
+		{name: "repeated", body: "@alice @alice @alice", want: []string{"alice"}, lookups: 1},
+		{name: "trailing punctuation", body: "thanks @alice. and @bob-", want: []string{"alice", "bob"}, lookups: 1},
+		{name: "punctuation in the name", body: "ping @carol.", want: []string{"carol."}, lookups: 1},
+		{name: "same user twice", body: "@alice and @alice.", want: []string{"alice"}, lookups: 1},
+		{name: "capped", body: strings.Join(many, " "), want: []string{"u0"}, lookups: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &countingUserRepository{UserRepository: users}
+			u := &commentUsecase{userRepo: repo}
+
+			mentions, err := u.resolveMentions(context.Background(), tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, mention := range mentions {
+				got = append(got, mention.Username)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveMentions(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+			if repo.lookups != tt.lookups {
+				t.Errorf("resolveMentions(%q) made %d lookups, want %d", tt.body, repo.lookups, tt.lookups)
+			}
+		})
+	}
+}
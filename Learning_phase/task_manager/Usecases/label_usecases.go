@@ -0,0 +1,144 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"time"
+	"unicode/utf8"
+)
+
+const maxLabelNameLength = 50
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+type LabelUsecase interface {
+	GetLabels(ctx context.Context) ([]domain.Label, error)
+	CreateLabel(ctx context.Context, actor domain.Actor, req domain.LabelRequest) (domain.Label, error)
+	UpdateLabel(ctx context.Context, actor domain.Actor, id string, req domain.LabelRequest) (domain.Label, error)
+	DeleteLabel(ctx context.Context, actor domain.Actor, id string) error
+}
+
+type labelUsecase struct {
+	labelRepo    repositories.LabelRepository
+	taskRepo     repositories.TaskRepository
+	auditUsecase AuditUsecase
+}
+
+func NewLabelUsecase(labelRepo repositories.LabelRepository, taskRepo repositories.TaskRepository, auditUsecase AuditUsecase) LabelUsecase {
+	return &labelUsecase{labelRepo: labelRepo, taskRepo: taskRepo, auditUsecase: auditUsecase}
+}
+
+func (u *labelUsecase) GetLabels(ctx context.Context) ([]domain.Label, error) {
+	return u.labelRepo.GetAll(ctx)
+}
+
+func (u *labelUsecase) CreateLabel(ctx context.Context, actor domain.Actor, req domain.LabelRequest) (domain.Label, error) {
+	label := domain.Label{
+		Name:      domain.NormalizeLabelName(req.Name),
+		Color:     strings.ToLower(strings.TrimSpace(req.Color)),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := validateLabel(label).Err(); err != nil {
+		return domain.Label{}, err
+	}
+	if err := u.checkNameFree(ctx, label.Name, ""); err != nil {
+		return domain.Label{}, err
+	}
+
+	created, err := u.labelRepo.Create(ctx, label)
+	if err != nil {
+		return domain.Label{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditLabelCreate, domain.AuditTargetLabel, created.ID.Hex(), nil, created)
+	return created, nil
+}
+
+// UpdateLabel changes a label's name or color. A rename is applied to every
+// task carrying the label before the definition changes, so a failed rename
+// can be retried: the definition still has the old name, and tasks already
+// renamed no longer match it.
+func (u *labelUsecase) UpdateLabel(ctx context.Context, actor domain.Actor, id string, req domain.LabelRequest) (domain.Label, error) {
+	existing, err := u.labelRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Label{}, err
+	}
+
+	label := existing
+	label.Name = domain.NormalizeLabelName(req.Name)
+	label.Color = strings.ToLower(strings.TrimSpace(req.Color))
+	if err := validateLabel(label).Err(); err != nil {
+		return domain.Label{}, err
+	}
+	if label.Name != existing.Name {
+		if err := u.checkNameFree(ctx, label.Name, id); err != nil {
+			return domain.Label{}, err
+		}
+	}
+
+	if label.Name != existing.Name {
+		if _, err := u.taskRepo.RenameLabel(ctx, existing.Name, label.Name); err != nil {
+			return domain.Label{}, err
+		}
+	}
+	updated, err := u.labelRepo.Update(ctx, id, label.Name, label.Color)
+	if err != nil {
+		return domain.Label{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditLabelUpdate, domain.AuditTargetLabel, id, existing, updated)
+	return updated, nil
+}
+
+// DeleteLabel removes a label and detaches it from every task. Tasks are
+// stripped before the definition goes, so a failed delete can be retried.
+func (u *labelUsecase) DeleteLabel(ctx context.Context, actor domain.Actor, id string) error {
+	existing, err := u.labelRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := u.taskRepo.StripLabel(ctx, existing.Name); err != nil {
+		return err
+	}
+	if err := u.labelRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditLabelDelete, domain.AuditTargetLabel, id, existing, nil)
+	return nil
+}
+
+func (u *labelUsecase) checkNameFree(ctx context.Context, name, id string) error {
+	other, err := u.labelRepo.GetByName(ctx, name)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if other.ID.Hex() != id {
+		return domain.Conflict(fmt.Sprintf("label %q already exists", name))
+	}
+	return nil
+}
+
+func validateLabel(label domain.Label) domain.FieldErrors {
+	var errs domain.FieldErrors
+
+	switch {
+	case label.Name == "":
+		errs.Add("name", "is required")
+	case utf8.RuneCountInString(label.Name) > maxLabelNameLength:
+		errs.Add("name", fmt.Sprintf("must be at most %d characters", maxLabelNameLength))
+	case strings.Contains(label.Name, ","):
+		errs.Add("name", "must not contain commas")
+	}
+	if !labelColorPattern.MatchString(label.Color) {
+		errs.Add("color", "must be a hex color such as #d73a4a")
+	}
+
+	return errs
+}
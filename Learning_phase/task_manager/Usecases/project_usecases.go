@@ -0,0 +1,247 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"time"
+	"unicode/utf8"
+)
+
+const maxProjectNameLength = 100
+
+var (
+	ErrLastProjectOwner = domain.NewError(domain.ErrConflict, "a project needs at least one owner")
+	ErrProjectNotEmpty  = domain.NewError(domain.ErrConflict, "project still has tasks")
+)
+
+var projectKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
+type ProjectUsecase interface {
+	GetProjects(ctx context.Context, actor domain.Actor) ([]domain.Project, error)
+	GetProject(ctx context.Context, actor domain.Actor, id string) (domain.Project, error)
+	CreateProject(ctx context.Context, actor domain.Actor, req domain.CreateProjectRequest) (domain.Project, error)
+	UpdateProject(ctx context.Context, actor domain.Actor, id string, req domain.UpdateProjectRequest) (domain.Project, error)
+	DeleteProject(ctx context.Context, actor domain.Actor, id string) error
+	SetMember(ctx context.Context, actor domain.Actor, id, username, role string) (domain.Project, error)
+	RemoveMember(ctx context.Context, actor domain.Actor, id, username string) (domain.Project, error)
+	MemberRole(ctx context.Context, projectID, userID string) (string, error)
+}
+
+type projectUsecase struct {
+	projectRepo  repositories.ProjectRepository
+	taskRepo     repositories.TaskRepository
+	userRepo     repositories.UserRepository
+	auditUsecase AuditUsecase
+}
+
+func NewProjectUsecase(projectRepo repositories.ProjectRepository, taskRepo repositories.TaskRepository, userRepo repositories.UserRepository, auditUsecase AuditUsecase) ProjectUsecase {
+	return &projectUsecase{projectRepo: projectRepo, taskRepo: taskRepo, userRepo: userRepo, auditUsecase: auditUsecase}
+}
+
+// GetProjects lists the projects the actor belongs to, or all of them for
+// holders of projects:manage.
+func (u *projectUsecase) GetProjects(ctx context.Context, actor domain.Actor) ([]domain.Project, error) {
+	if actor.Has(domain.PermProjectsManage) {
+		return u.projectRepo.GetAll(ctx, "")
+	}
+	return u.projectRepo.GetAll(ctx, actor.UserID)
+}
+
+func (u *projectUsecase) GetProject(ctx context.Context, actor domain.Actor, id string) (domain.Project, error) {
+	return u.projectRepo.GetByID(ctx, id)
+}
+
+func (u *projectUsecase) CreateProject(ctx context.Context, actor domain.Actor, req domain.CreateProjectRequest) (domain.Project, error) {
+	project := domain.Project{
+		Key:         strings.ToUpper(strings.TrimSpace(req.Key)),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Members:     []domain.ProjectMember{{UserID: actor.UserID, Username: actor.Username, Role: domain.ProjectRoleOwner}},
+		CreatedBy:   actor.UserID,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := validateProject(project).Err(); err != nil {
+		return domain.Project{}, err
+	}
+
+	created, err := u.projectRepo.Create(ctx, project)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditProjectCreate, domain.AuditTargetProject, created.ID.Hex(), nil, created)
+	return created, nil
+}
+
+func (u *projectUsecase) UpdateProject(ctx context.Context, actor domain.Actor, id string, req domain.UpdateProjectRequest) (domain.Project, error) {
+	existing, err := u.projectRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	project := existing
+	project.Name = strings.TrimSpace(req.Name)
+	project.Description = req.Description
+	if err := validateProject(project).Err(); err != nil {
+		return domain.Project{}, err
+	}
+
+	updated, err := u.projectRepo.Update(ctx, id, project.Name, project.Description)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditProjectUpdate, domain.AuditTargetProject, id, existing, updated)
+	return updated, nil
+}
+
+// DeleteProject removes an empty project. Tasks, including those in the
+// trash, have to be moved out or purged first.
+func (u *projectUsecase) DeleteProject(ctx context.Context, actor domain.Actor, id string) error {
+	existing, err := u.projectRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, deleted := range []bool{false, true} {
+		tasks, err := u.taskRepo.GetAll(ctx, domain.TaskQuery{ProjectID: id, Deleted: deleted, Limit: 1})
+		if err != nil {
+			return err
+		}
+		if len(tasks) > 0 {
+			return ErrProjectNotEmpty
+		}
+	}
+
+	if err := u.projectRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditProjectDelete, domain.AuditTargetProject, id, existing, nil)
+	return nil
+}
+
+func (u *projectUsecase) SetMember(ctx context.Context, actor domain.Actor, id, username, role string) (domain.Project, error) {
+	if !domain.IsProjectRole(role) {
+		return domain.Project{}, domain.Validation(fmt.Sprintf("role must be one of %s, %s or %s", domain.ProjectRoleViewer, domain.ProjectRoleMember, domain.ProjectRoleOwner))
+	}
+	existing, err := u.projectRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	user, err := u.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	if role != domain.ProjectRoleOwner && isLastOwner(existing, user.ID.Hex()) {
+		return domain.Project{}, ErrLastProjectOwner
+	}
+
+	updated, err := u.projectRepo.SetMember(ctx, id, domain.ProjectMember{UserID: user.ID.Hex(), Username: user.Username, Role: role})
+	if err != nil {
+		return domain.Project{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditProjectMember, domain.AuditTargetProject, id, existing, updated)
+	return updated, nil
+}
+
+func (u *projectUsecase) RemoveMember(ctx context.Context, actor domain.Actor, id, username string) (domain.Project, error) {
+	existing, err := u.projectRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	user, err := u.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	if _, ok := existing.Member(user.ID.Hex()); !ok {
+		return domain.Project{}, domain.NotFound("project member")
+	}
+	if isLastOwner(existing, user.ID.Hex()) {
+		return domain.Project{}, ErrLastProjectOwner
+	}
+
+	updated, err := u.projectRepo.RemoveMember(ctx, id, user.ID.Hex())
+	if err != nil {
+		return domain.Project{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditProjectMember, domain.AuditTargetProject, id, existing, updated)
+	return updated, nil
+}
+
+// MemberRole returns the role userID holds in the project, or "" if the user
+// is not a member.
+func (u *projectUsecase) MemberRole(ctx context.Context, projectID, userID string) (string, error) {
+	project, err := u.projectRepo.GetByID(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+	member, _ := project.Member(userID)
+	return member.Role, nil
+}
+
+// actorInProject returns actor as it acts on the tasks of projectID. Routes
+// nested under a project already carry the caller's project role; on the
+// flat routes it is resolved here the same way RequireProjectRole does, so a
+// task's project always decides what the caller may do with it. Callers
+// outside the project get a 404 for resource.
+func actorInProject(ctx context.Context, projectRepo repositories.ProjectRepository, actor domain.Actor, projectID, resource string) (domain.Actor, error) {
+	if actor.ProjectID != "" || projectID == "" {
+		if projectID != actor.ProjectID {
+			return domain.Actor{}, domain.NotFound(resource)
+		}
+		return actor, nil
+	}
+
+	role := domain.ProjectRoleOwner
+	if !actor.Has(domain.PermProjectsManage) {
+		project, err := projectRepo.GetByID(ctx, projectID)
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrInvalidID) {
+			return domain.Actor{}, domain.NotFound(resource)
+		}
+		if err != nil {
+			return domain.Actor{}, err
+		}
+		member, ok := project.Member(actor.UserID)
+		if !ok {
+			return domain.Actor{}, domain.NotFound(resource)
+		}
+		role = member.Role
+	}
+
+	actor.ProjectID = projectID
+	actor.Permissions = domain.ProjectPermissions(actor.Permissions, role)
+	return actor, nil
+}
+
+func isLastOwner(project domain.Project, userID string) bool {
+	member, ok := project.Member(userID)
+	if !ok || member.Role != domain.ProjectRoleOwner {
+		return false
+	}
+	for _, m := range project.Members {
+		if m.Role == domain.ProjectRoleOwner && m.UserID != userID {
+			return false
+		}
+	}
+	return true
+}
+
+func validateProject(project domain.Project) domain.FieldErrors {
+	var errs domain.FieldErrors
+
+	if !projectKeyPattern.MatchString(project.Key) {
+		errs.Add("key", "must be 2 to 10 letters or digits, starting with a letter")
+	}
+	switch {
+	case project.Name == "":
+		errs.Add("name", "is required")
+	case utf8.RuneCountInString(project.Name) > maxProjectNameLength:
+		errs.Add("name", fmt.Sprintf("must be at most %d characters", maxProjectNameLength))
+	}
+
+	return errs
+}
@@ -0,0 +1,73 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"time"
+)
+
+// recurrenceBatchSize caps how many series are advanced per run; anything
+// left over is picked up by the next one.
+const recurrenceBatchSize = 100
+
+// schedulerActor is recorded in the audit log for occurrences the scheduler
+// creates.
+var schedulerActor = domain.Actor{Username: "scheduler"}
+
+// RecurrenceScheduler creates the next occurrence of recurring tasks that
+// are done or whose due date has passed. Completing a task through the API
+// creates its next occurrence straight away; the scheduler covers overdue
+// tasks and retries anything that failed then.
+type RecurrenceScheduler struct {
+	taskRepo    repositories.TaskRepository
+	occurrences occurrenceCreator
+	interval    time.Duration
+}
+
+func NewRecurrenceScheduler(taskRepo repositories.TaskRepository, auditUsecase AuditUsecase, interval time.Duration) *RecurrenceScheduler {
+	return &RecurrenceScheduler{
+		taskRepo:    taskRepo,
+		occurrences: occurrenceCreator{taskRepo: taskRepo, auditUsecase: auditUsecase},
+		interval:    interval,
+	}
+}
+
+func (s *RecurrenceScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.ScheduleOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *RecurrenceScheduler) ScheduleOnce(ctx context.Context) {
+	now := time.Now()
+	tasks, err := s.taskRepo.GetPendingOccurrences(ctx, now, recurrenceBatchSize)
+	if err != nil {
+		log.Printf("recurrence scheduler: %v", err)
+		return
+	}
+
+	created := 0
+	for _, task := range tasks {
+		ok, err := s.occurrences.createNext(ctx, schedulerActor, task, now)
+		if err != nil {
+			log.Printf("recurrence scheduler: task %s: %v", task.ID.Hex(), err)
+			continue
+		}
+		if ok {
+			created++
+		}
+	}
+	if created > 0 {
+		log.Printf("recurrence scheduler: created %d occurrence(s)", created)
+	}
+}
@@ -0,0 +1,182 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"task_manager/Domain"
+	"task_manager/Infrastructure"
+	"task_manager/Repositories"
+	"time"
+)
+
+// ReminderWorker periodically looks for tasks that are due soon or overdue
+// and not yet done, and sends each reminder through the notifier once.
+// Reminders are recorded before they are sent, so a restart or a second
+// server never repeats one; one whose delivery fails is forgotten again and
+// retried on the next run.
+type ReminderWorker struct {
+	taskRepo     repositories.TaskRepository
+	userRepo     repositories.UserRepository
+	reminderRepo repositories.ReminderRepository
+	notifier     infrastructure.Notifier
+	policy       domain.ReminderPolicy
+	interval     time.Duration
+}
+
+func NewReminderWorker(taskRepo repositories.TaskRepository, userRepo repositories.UserRepository, reminderRepo repositories.ReminderRepository, notifier infrastructure.Notifier, policy domain.ReminderPolicy, interval time.Duration) *ReminderWorker {
+	windows := slices.Clone(policy.Windows)
+	slices.Sort(windows)
+	policy.Windows = windows
+	return &ReminderWorker{taskRepo: taskRepo, userRepo: userRepo, reminderRepo: reminderRepo, notifier: notifier, policy: policy, interval: interval}
+}
+
+func (w *ReminderWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.ScanOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reminderScanPageSize is how many tasks ScanOnce loads at a time.
+const reminderScanPageSize = 200
+
+func (w *ReminderWorker) ScanOnce(ctx context.Context) {
+	now := time.Now().UTC()
+	from := now.Add(-w.policy.OverdueLookback)
+	to := now
+	if len(w.policy.Windows) > 0 {
+		to = now.Add(w.policy.Windows[len(w.policy.Windows)-1])
+	}
+	query := domain.TaskQuery{
+		DueAfter:        &from,
+		DueBefore:       &to,
+		ExcludeStatuses: []string{domain.StatusDone, domain.StatusArchived},
+		SortBy:          "due_date",
+		Limit:           reminderScanPageSize,
+	}
+
+	sent := 0
+	for {
+		tasks, err := w.taskRepo.GetAll(ctx, query)
+		if err != nil {
+			log.Printf("reminder worker: %v", err)
+			break
+		}
+		sent += w.remind(ctx, tasks, now)
+		if len(tasks) < query.Limit {
+			break
+		}
+		last := tasks[len(tasks)-1]
+		query.Cursor = &domain.TaskCursor{Value: last.DueDate, ID: last.ID}
+	}
+	if sent > 0 {
+		log.Printf("reminder worker: sent %d reminder(s)", sent)
+	}
+}
+
+type reminderKey struct {
+	taskID, kind, window string
+	dueDate              int64
+}
+
+func keyOf(reminder domain.Reminder) reminderKey {
+	return reminderKey{taskID: reminder.TaskID, kind: reminder.Kind, window: reminder.Window, dueDate: reminder.DueDate.UnixMilli()}
+}
+
+// remind sends the reminders tasks are due for at now, skipping the ones
+// already recorded, and returns how many were delivered.
+func (w *ReminderWorker) remind(ctx context.Context, tasks []domain.Task, now time.Time) int {
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID.Hex()
+	}
+	recorded, err := w.reminderRepo.Recorded(ctx, ids)
+	if err != nil {
+		log.Printf("reminder worker: %v", err)
+		return 0
+	}
+	seen := make(map[reminderKey]bool, len(recorded))
+	for _, reminder := range recorded {
+		seen[keyOf(reminder)] = true
+	}
+
+	sent := 0
+	for _, task := range tasks {
+		reminder, ok := w.reminderFor(task, now)
+		if !ok || seen[keyOf(reminder)] {
+			continue
+		}
+		delivered, err := w.send(ctx, reminder, task.CreatedBy)
+		if err != nil {
+			log.Printf("reminder worker: task %s: %v", reminder.TaskID, err)
+			continue
+		}
+		if delivered {
+			sent++
+		}
+	}
+	return sent
+}
+
+// reminderFor picks the reminder task is due for at now. Before the due
+// date that is the smallest window the due date falls into, so a task first
+// seen close to its due date gets one reminder rather than one per window.
+func (w *ReminderWorker) reminderFor(task domain.Task, now time.Time) (domain.Reminder, bool) {
+	reminder := domain.Reminder{
+		TaskID:     task.ID.Hex(),
+		DueDate:    task.DueDate.UTC(),
+		Title:      task.Title,
+		ProjectID:  task.ProjectID,
+		AssigneeID: task.AssigneeID,
+	}
+	left := task.DueDate.Sub(now)
+	if left <= 0 {
+		reminder.Kind = domain.ReminderOverdue
+		return reminder, true
+	}
+	for _, window := range w.policy.Windows {
+		if left <= window {
+			reminder.Kind, reminder.Window = domain.ReminderDueSoon, domain.FormatWindow(window)
+			return reminder, true
+		}
+	}
+	return domain.Reminder{}, false
+}
+
+// send claims reminder and delivers it to the assignee, or to createdBy for
+// an unassigned task, reporting false when it had already been sent. A
+// reminder with nobody to go to stays claimed, so it is not retried.
+func (w *ReminderWorker) send(ctx context.Context, reminder domain.Reminder, createdBy string) (bool, error) {
+	reminder.SentAt = time.Now().UTC()
+	claimed, err := w.reminderRepo.Claim(ctx, reminder)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	if user, err := w.userRepo.GetByID(ctx, reminder.AssigneeID); err == nil {
+		reminder.Assignee, reminder.Recipient = user.Username, user.Username
+	} else if user, err := w.userRepo.GetByID(ctx, createdBy); err == nil {
+		reminder.Recipient = user.Username
+	}
+	err = w.notifier.Notify(ctx, reminder)
+	if errors.Is(err, infrastructure.ErrNoRecipient) {
+		log.Printf("reminder worker: task %s: %v, skipped", reminder.TaskID, err)
+		return false, nil
+	}
+	if err != nil {
+		if releaseErr := w.reminderRepo.Release(context.WithoutCancel(ctx), reminder); releaseErr != nil {
+			log.Printf("reminder worker: releasing reminder for task %s: %v", reminder.TaskID, releaseErr)
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,90 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+)
+
+var (
+	ErrInvalidRole  = domain.NewError(domain.ErrValidation, "invalid role")
+	ErrRoleNotFound = domain.NewError(domain.ErrNotFound, "role not found")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+type RoleUsecase interface {
+	GetRoles(ctx context.Context) ([]domain.Role, error)
+	GetRole(ctx context.Context, name string) (domain.Role, error)
+	CreateRole(ctx context.Context, role domain.Role) (domain.Role, error)
+	Permissions(ctx context.Context, roleName string) ([]string, error)
+}
+
+type roleUsecase struct {
+	roleRepo repositories.RoleRepository
+}
+
+func NewRoleUsecase(roleRepo repositories.RoleRepository) RoleUsecase {
+	return &roleUsecase{roleRepo: roleRepo}
+}
+
+func (u *roleUsecase) GetRoles(ctx context.Context) ([]domain.Role, error) {
+	custom, err := u.roleRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]domain.Role{}, domain.BuiltInRoles...), custom...), nil
+}
+
+func (u *roleUsecase) GetRole(ctx context.Context, name string) (domain.Role, error) {
+	for _, role := range domain.BuiltInRoles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+
+	role, err := u.roleRepo.GetByName(ctx, name)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.Role{}, ErrRoleNotFound
+	}
+	if err != nil {
+		return domain.Role{}, err
+	}
+	return role, nil
+}
+
+func (u *roleUsecase) CreateRole(ctx context.Context, role domain.Role) (domain.Role, error) {
+	if !roleNamePattern.MatchString(role.Name) {
+		return domain.Role{}, fmt.Errorf("%w: name must be 2-32 lowercase letters, digits, '-' or '_'", ErrInvalidRole)
+	}
+	if _, err := u.GetRole(ctx, role.Name); err == nil {
+		return domain.Role{}, domain.Conflict(fmt.Sprintf("role %q already exists", role.Name))
+	}
+
+	seen := make(map[string]bool)
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		if !domain.IsPermission(permission) {
+			return domain.Role{}, fmt.Errorf("%w: unknown permission %q", ErrInvalidRole, permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+
+	role.Permissions = permissions
+	role.BuiltIn = false
+	return u.roleRepo.Create(ctx, role)
+}
+
+func (u *roleUsecase) Permissions(ctx context.Context, roleName string) ([]string, error) {
+	role, err := u.GetRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	return role.Permissions, nil
+}
@@ -0,0 +1,361 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+)
+
+// maxGraphNodes bounds both cycle detection and GET /tasks/:id/graph.
+const maxGraphNodes = 500
+
+// ErrDependencyGraphTooLarge is returned when cycle detection gives up
+// before it could rule out a cycle; the edge is refused rather than risked.
+var ErrDependencyGraphTooLarge = &domain.Error{
+	Kind:    domain.ErrConflict,
+	Message: "the dependency graph is too large to check for cycles",
+	Details: map[string]interface{}{"code": "dependency_graph_too_large", "max_nodes": maxGraphNodes},
+}
+
+func errDependencyCycle(cycle []string) error {
+	return &domain.Error{
+		Kind:    domain.ErrConflict,
+		Message: "the dependency would create a cycle",
+		Details: map[string]interface{}{"code": "dependency_cycle", "cycle": cycle},
+	}
+}
+
+// SetParent makes parentID the parent of task id, or detaches it from its
+// parent when parentID is empty.
+func (u *taskUsecase) SetParent(ctx context.Context, actor domain.Actor, id, parentID string, version int64) (domain.Task, error) {
+	existing, actor, err := u.loadEdgeTarget(ctx, actor, id, version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if parentID == existing.ParentID {
+		return existing, nil
+	}
+
+	if parentID != "" {
+		if _, err := u.GetTaskByID(ctx, actor, parentID); err != nil {
+			return domain.Task{}, err
+		}
+		if err := u.checkParentCycle(ctx, id, parentID); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	updated, err := u.taskRepo.SetParent(ctx, id, parentID, existing.Version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	// The check above raced any concurrent edge writes; checking again now
+	// that ours is in place catches a cycle closed by two of them at once.
+	if parentID != "" {
+		if err := u.checkParentCycle(ctx, id, parentID); err != nil {
+			if _, undoErr := u.taskRepo.SetParent(context.WithoutCancel(ctx), id, existing.ParentID, updated.Version); undoErr != nil {
+				log.Printf("dependencies: restoring the parent of task %s: %v", id, undoErr)
+			}
+			return domain.Task{}, err
+		}
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskDependency, domain.AuditTargetTask, id, existing, updated)
+	return updated, nil
+}
+
+func (u *taskUsecase) AddBlocker(ctx context.Context, actor domain.Actor, id, blockerID string, version int64) (domain.Task, error) {
+	existing, actor, err := u.loadEdgeTarget(ctx, actor, id, version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if blockerID == id {
+		return domain.Task{}, errDependencyCycle([]string{id, id})
+	}
+	for _, b := range existing.BlockedBy {
+		if b == blockerID {
+			return existing, nil
+		}
+	}
+	if _, err := u.GetTaskByID(ctx, actor, blockerID); err != nil {
+		return domain.Task{}, err
+	}
+
+	if err := u.checkBlockerCycle(ctx, id, blockerID); err != nil {
+		return domain.Task{}, err
+	}
+
+	updated, err := u.taskRepo.AddBlocker(ctx, id, blockerID, existing.Version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if err := u.checkBlockerCycle(ctx, id, blockerID); err != nil {
+		if _, undoErr := u.taskRepo.RemoveBlocker(context.WithoutCancel(ctx), id, blockerID, domain.AnyVersion); undoErr != nil {
+			log.Printf("dependencies: removing blocker %s from task %s: %v", blockerID, id, undoErr)
+		}
+		return domain.Task{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskDependency, domain.AuditTargetTask, id, existing, updated)
+	return updated, nil
+}
+
+func (u *taskUsecase) RemoveBlocker(ctx context.Context, actor domain.Actor, id, blockerID string, version int64) (domain.Task, error) {
+	existing, actor, err := u.loadEdgeTarget(ctx, actor, id, version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	found := false
+	for _, b := range existing.BlockedBy {
+		found = found || b == blockerID
+	}
+	if !found {
+		return domain.Task{}, domain.NotFound("blocker")
+	}
+
+	updated, err := u.taskRepo.RemoveBlocker(ctx, id, blockerID, existing.Version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskDependency, domain.AuditTargetTask, id, existing, updated)
+	return updated, nil
+}
+
+func (u *taskUsecase) loadEdgeTarget(ctx context.Context, actor domain.Actor, id string, version int64) (domain.Task, domain.Actor, error) {
+	task, actor, err := u.loadVersion(ctx, actor, id, version)
+	if err != nil {
+		return domain.Task{}, domain.Actor{}, err
+	}
+	if !isPermitted(actor, task, domain.PermTasksWriteAny, domain.PermTasksWriteOwn) {
+		return domain.Task{}, domain.Actor{}, ErrForbidden
+	}
+	return task, actor, nil
+}
+
+// checkParentCycle walks up from parentID; reaching id means id is one of
+// its ancestors.
+func (u *taskUsecase) checkParentCycle(ctx context.Context, id, parentID string) error {
+	cycle := []string{id}
+	for current := parentID; current != ""; {
+		cycle = append(cycle, current)
+		if current == id {
+			return errDependencyCycle(cycle)
+		}
+		if len(cycle) > maxGraphNodes {
+			return ErrDependencyGraphTooLarge
+		}
+		task, err := u.taskRepo.GetByID(ctx, current)
+		if errors.Is(err, domain.ErrNotFound) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		current = task.ParentID
+	}
+	return nil
+}
+
+// checkBlockerCycle fails when blockerID already depends, directly or
+// not, on id.
+func (u *taskUsecase) checkBlockerCycle(ctx context.Context, id, blockerID string) error {
+	path, err := u.findBlockerPath(ctx, blockerID, id)
+	if err != nil {
+		return err
+	}
+	if path != nil {
+		return errDependencyCycle(append([]string{id}, path...))
+	}
+	return nil
+}
+
+// findBlockerPath searches the blocked-by edges from start for target and
+// returns the path between them, or nil if target cannot be reached. A
+// search cut short by maxGraphNodes is an error, not a missing path.
+func (u *taskUsecase) findBlockerPath(ctx context.Context, start, target string) ([]string, error) {
+	previous := map[string]string{start: ""}
+	queue := []string{start}
+	for len(queue) > 0 && len(previous) <= maxGraphNodes {
+		current := queue[0]
+		queue = queue[1:]
+		if current == target {
+			var path []string
+			for id := current; id != ""; id = previous[id] {
+				path = append([]string{id}, path...)
+			}
+			return path, nil
+		}
+
+		task, err := u.taskRepo.GetByID(ctx, current)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, next := range task.BlockedBy {
+			if _, seen := previous[next]; !seen {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	if len(queue) > 0 {
+		return nil, ErrDependencyGraphTooLarge
+	}
+	return nil, nil
+}
+
+// openBlockers lists the blockers of task that are neither done nor
+// archived. Blockers that have since been deleted no longer count.
+func (u *taskUsecase) openBlockers(ctx context.Context, task domain.Task) ([]string, error) {
+	open := []string{}
+	for _, id := range task.BlockedBy {
+		blocker, err := u.taskRepo.GetByID(ctx, id)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if blocker.Status != domain.StatusDone && blocker.Status != domain.StatusArchived {
+			open = append(open, id)
+		}
+	}
+	return open, nil
+}
+
+func (u *taskUsecase) GetTaskGraph(ctx context.Context, actor domain.Actor, id string) (domain.TaskGraph, error) {
+	root, actor, err := u.getInScope(ctx, actor, id)
+	if err != nil {
+		return domain.TaskGraph{}, err
+	}
+	if !isPermitted(actor, root, domain.PermTasksReadAny, domain.PermTasksReadOwn) {
+		return domain.TaskGraph{}, ErrForbidden
+	}
+
+	g := &taskGraphBuilder{
+		repo:      u.taskRepo,
+		tasks:     map[string]domain.Task{id: root},
+		order:     []string{id},
+		edges:     map[domain.TaskGraphEdge]bool{},
+		edgeOrder: []domain.TaskGraphEdge{},
+	}
+	for _, neighbours := range []func(context.Context, domain.Task) ([]domain.Task, []domain.TaskGraphEdge, error){
+		g.blockers, g.dependents, g.parent, g.subtasks,
+	} {
+		if err := g.walk(ctx, root, neighbours); err != nil {
+			return domain.TaskGraph{}, err
+		}
+	}
+
+	graph := domain.TaskGraph{Root: id, Nodes: []domain.TaskGraphNode{}, Edges: g.edgeOrder, Truncated: g.truncated}
+	for _, taskID := range g.order {
+		node := domain.TaskGraphNode{ID: taskID}
+		task := g.tasks[taskID]
+		if task.ProjectID == actor.ProjectID && isPermitted(actor, task, domain.PermTasksReadAny, domain.PermTasksReadOwn) {
+			node.Title, node.Status = task.Title, task.Status
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	return graph, nil
+}
+
+type taskGraphBuilder struct {
+	repo      repositories.TaskRepository
+	tasks     map[string]domain.Task
+	order     []string
+	edges     map[domain.TaskGraphEdge]bool
+	edgeOrder []domain.TaskGraphEdge
+	truncated bool
+}
+
+// walk follows one kind of edge from start in one direction as far as it
+// goes, adding every task and edge it finds.
+func (g *taskGraphBuilder) walk(ctx context.Context, start domain.Task, neighbours func(context.Context, domain.Task) ([]domain.Task, []domain.TaskGraphEdge, error)) error {
+	visited := map[string]bool{start.ID.Hex(): true}
+	queue := []domain.Task{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		tasks, edges, err := neighbours(ctx, current)
+		if err != nil {
+			return err
+		}
+		for i, task := range tasks {
+			taskID := task.ID.Hex()
+			if _, known := g.tasks[taskID]; !known {
+				if len(g.tasks) >= maxGraphNodes {
+					g.truncated = true
+					continue
+				}
+				g.tasks[taskID] = task
+				g.order = append(g.order, taskID)
+			}
+			if !g.edges[edges[i]] {
+				g.edges[edges[i]] = true
+				g.edgeOrder = append(g.edgeOrder, edges[i])
+			}
+			if !visited[taskID] {
+				visited[taskID] = true
+				queue = append(queue, task)
+			}
+		}
+	}
+	return nil
+}
+
+func (g *taskGraphBuilder) load(ctx context.Context, ids ...string) ([]domain.Task, error) {
+	var tasks []domain.Task
+	for _, id := range ids {
+		task, err := g.repo.GetByID(ctx, id)
+		if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrInvalidID) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (g *taskGraphBuilder) blockers(ctx context.Context, task domain.Task) ([]domain.Task, []domain.TaskGraphEdge, error) {
+	blockers, err := g.load(ctx, task.BlockedBy...)
+	return blockers, edgesTo(blockers, task, domain.EdgeBlocks), err
+}
+
+func (g *taskGraphBuilder) dependents(ctx context.Context, task domain.Task) ([]domain.Task, []domain.TaskGraphEdge, error) {
+	dependents, err := g.repo.GetAll(ctx, domain.TaskQuery{BlockedBy: task.ID.Hex()})
+	return dependents, edgesFrom(task, dependents, domain.EdgeBlocks), err
+}
+
+func (g *taskGraphBuilder) parent(ctx context.Context, task domain.Task) ([]domain.Task, []domain.TaskGraphEdge, error) {
+	if task.ParentID == "" {
+		return nil, nil, nil
+	}
+	parents, err := g.load(ctx, task.ParentID)
+	return parents, edgesTo(parents, task, domain.EdgeSubtask), err
+}
+
+func (g *taskGraphBuilder) subtasks(ctx context.Context, task domain.Task) ([]domain.Task, []domain.TaskGraphEdge, error) {
+	subtasks, err := g.repo.GetAll(ctx, domain.TaskQuery{ParentID: task.ID.Hex()})
+	return subtasks, edgesFrom(task, subtasks, domain.EdgeSubtask), err
+}
+
+func edgesTo(from []domain.Task, to domain.Task, edgeType string) []domain.TaskGraphEdge {
+	edges := make([]domain.TaskGraphEdge, len(from))
+	for i, task := range from {
+		edges[i] = domain.TaskGraphEdge{From: task.ID.Hex(), To: to.ID.Hex(), Type: edgeType}
+	}
+	return edges
+}
+
+func edgesFrom(from domain.Task, to []domain.Task, edgeType string) []domain.TaskGraphEdge {
+	edges := make([]domain.TaskGraphEdge, len(to))
+	for i, task := range to {
+		edges[i] = domain.TaskGraphEdge{From: from.ID.Hex(), To: task.ID.Hex(), Type: edgeType}
+	}
+	return edges
+}
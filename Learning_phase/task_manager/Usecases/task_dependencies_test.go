@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// blockerGraph creates n tasks in a fresh repository and returns a usecase
+// over it with their ids.
+func blockerGraph(t *testing.T, n int) (*taskUsecase, []string) {
+	t.Helper()
+	repo := repositories.NewMemoryTaskRepository()
+	ids := make([]string, n)
+	for i := range ids {
+		task, err := repo.Create(context.Background(), domain.Task{Title: "task"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = task.ID.Hex()
+	}
+	return &taskUsecase{taskRepo: repo}, ids
+}
+
+func block(t *testing.T, u *taskUsecase, id, blockerID string) {
+	t.Helper()
+	if _, err := u.taskRepo.AddBlocker(context.Background(), id, blockerID, domain.AnyVersion); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindBlockerPath(t *testing.T) {
+	missing := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name  string
+		edges [][2]int // task, blocker
+		start int
+		// target is an index into the tasks, or -1 for a task that does not
+		// exist.
+		target int
+		want   []int
+	}{
+		{name: "start is the target", start: 0, target: 0, want: []int{0}},
+		{name: "unrelated", start: 0, target: 1},
+		{name: "direct", edges: [][2]int{{0, 1}}, start: 0, target: 1, want: []int{0, 1}},
+		{name: "edges point one way", edges: [][2]int{{0, 1}}, start: 1, target: 0},
+		{name: "transitive", edges: [][2]int{{0, 1}, {1, 2}}, start: 0, target: 2, want: []int{0, 1, 2}},
+		{name: "shortest path", edges: [][2]int{{0, 1}, {1, 2}, {2, 3}, {0, 3}}, start: 0, target: 3, want: []int{0, 3}},
+		{name: "existing cycle", edges: [][2]int{{0, 1}, {1, 0}}, start: 0, target: 2},
+		{name: "missing blocker", edges: [][2]int{{0, -1}}, start: 0, target: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, ids := blockerGraph(t, 4)
+			id := func(i int) string {
+				if i < 0 {
+					return missing
+				}
+				return ids[i]
+			}
+			for _, edge := range tt.edges {
+				block(t, u, id(edge[0]), id(edge[1]))
+			}
+
+			got, err := u.findBlockerPath(context.Background(), id(tt.start), id(tt.target))
+			if err != nil {
+				t.Fatalf("findBlockerPath() error = %v", err)
+			}
+			var want []string
+			for _, i := range tt.want {
+				want = append(want, id(i))
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("findBlockerPath() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFindBlockerPathTooLarge(t *testing.T) {
+	u, ids := blockerGraph(t, maxGraphNodes+2)
+	for i := 0; i+1 < len(ids); i++ {
+		block(t, u, ids[i], ids[i+1])
+	}
+
+	_, err := u.findBlockerPath(context.Background(), ids[0], ids[len(ids)-1])
+	if !errors.Is(err, ErrDependencyGraphTooLarge) {
+		t.Errorf("findBlockerPath() error = %v, want %v", err, ErrDependencyGraphTooLarge)
+	}
+
+	path, err := u.findBlockerPath(context.Background(), ids[0], ids[10])
+	if err != nil || len(path) != 11 {
+		t.Errorf("findBlockerPath() = %d tasks, %v; want 11 tasks", len(path), err)
+	}
+}
@@ -0,0 +1,56 @@
+package usecases
+
+import (
+	"context"
+	"task_manager/Domain"
+)
+
+// AttachLabel adds an existing label to task id. Attaching a label the task
+// already carries is a no-op.
+func (u *taskUsecase) AttachLabel(ctx context.Context, actor domain.Actor, id, label string, version int64) (domain.Task, error) {
+	existing, actor, err := u.loadEdgeTarget(ctx, actor, id, version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	def, err := u.labelRepo.GetByName(ctx, domain.NormalizeLabelName(label))
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if containsLabel(existing.Labels, def.Name) {
+		return existing, nil
+	}
+
+	updated, err := u.taskRepo.AddLabel(ctx, id, def.Name, existing.Version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskLabel, domain.AuditTargetTask, id, existing, updated)
+	return updated, nil
+}
+
+func (u *taskUsecase) DetachLabel(ctx context.Context, actor domain.Actor, id, label string, version int64) (domain.Task, error) {
+	existing, actor, err := u.loadEdgeTarget(ctx, actor, id, version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	label = domain.NormalizeLabelName(label)
+	if !containsLabel(existing.Labels, label) {
+		return domain.Task{}, domain.NotFound("label")
+	}
+
+	updated, err := u.taskRepo.RemoveLabel(ctx, id, label, existing.Version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskLabel, domain.AuditTargetTask, id, existing, updated)
+	return updated, nil
+}
+
+func containsLabel(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,332 @@
+package usecases
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"task_manager/Domain"
+)
+
+var ErrInvalidPatch = domain.NewError(domain.ErrUnprocessable, "invalid patch")
+
+var readOnlyTaskFields = []string{"id", "created_by", "status_history", "parent_id", "blocked_by", "project_id", "labels", "recurrence"}
+
+// patchTaskDocument applies an RFC 7396 merge patch or RFC 6902 JSON Patch
+// to the JSON form of task and decodes the result.
+func patchTaskDocument(task domain.Task, format domain.PatchFormat, document []byte) (domain.Task, error) {
+	original, err := json.Marshal(task)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	var before, after interface{}
+	if err := json.Unmarshal(original, &before); err != nil {
+		return domain.Task{}, err
+	}
+	if err := json.Unmarshal(original, &after); err != nil {
+		return domain.Task{}, err
+	}
+
+	switch format {
+	case domain.MergePatch:
+		var patch interface{}
+		if err := json.Unmarshal(document, &patch); err != nil {
+			return domain.Task{}, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		if _, ok := patch.(map[string]interface{}); !ok {
+			return domain.Task{}, fmt.Errorf("%w: merge patch must be a JSON object", ErrInvalidPatch)
+		}
+		after = mergePatch(after, patch)
+	case domain.JSONPatch:
+		var ops []jsonPatchOperation
+		if err := json.Unmarshal(document, &ops); err != nil {
+			return domain.Task{}, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+		for i, op := range ops {
+			if after, err = op.apply(after); err != nil {
+				return domain.Task{}, fmt.Errorf("%w: operation %d: %v", ErrInvalidPatch, i, err)
+			}
+		}
+	default:
+		return domain.Task{}, fmt.Errorf("%w: unsupported patch format %q", ErrInvalidPatch, format)
+	}
+
+	beforeObj := before.(map[string]interface{})
+	afterObj, ok := after.(map[string]interface{})
+	if !ok {
+		return domain.Task{}, fmt.Errorf("%w: patched task must be a JSON object", ErrInvalidPatch)
+	}
+	for _, field := range readOnlyTaskFields {
+		if !reflect.DeepEqual(beforeObj[field], afterObj[field]) {
+			return domain.Task{}, fmt.Errorf("%w: field %q is read-only", ErrInvalidPatch, field)
+		}
+	}
+
+	patched, err := json.Marshal(afterObj)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	var result domain.Task
+	if err := decoder.Decode(&result); err != nil {
+		return domain.Task{}, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return result, nil
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = mergePatch(targetObj[key], value)
+		}
+	}
+	return targetObj
+}
+
+type jsonPatchOperation struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	From  string           `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+func (op jsonPatchOperation) value() (interface{}, error) {
+	if op.Value == nil {
+		return nil, fmt.Errorf("%q requires a value", op.Op)
+	}
+	var value interface{}
+	err := json.Unmarshal(*op.Value, &value)
+	return value, err
+}
+
+func (op jsonPatchOperation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, value)
+	case "remove":
+		_, doc, err = pointerRemove(doc, path)
+		return doc, err
+	case "replace":
+		value, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := pointerGet(doc, path); err != nil {
+			return nil, err
+		}
+		if _, doc, err = pointerRemove(doc, path); err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, value)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if op.Op == "move" {
+			if len(from) < len(path) && reflect.DeepEqual(from, path[:len(from)]) {
+				return nil, errors.New("cannot move a value into one of its children")
+			}
+			value, doc, err = pointerRemove(doc, from)
+		} else {
+			value, err = pointerGet(doc, from)
+			if err == nil {
+				value, err = deepCopy(value)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(doc, path, value)
+	case "test":
+		expected, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		actual, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			return nil, fmt.Errorf("test failed at %q", op.Path)
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	max := length - 1
+	if allowEnd {
+		max = length
+	}
+	if index > max {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
+
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path member %q not found", token)
+			}
+			doc = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[index]
+		default:
+			return nil, fmt.Errorf("cannot traverse into %q", token)
+		}
+	}
+	return doc, nil
+}
+
+// updateParent walks to the container holding the last path token and
+// replaces it with whatever fn returns, rebuilding the path back to the root.
+func updateParent(doc interface{}, path []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("path member %q not found", path[0])
+		}
+		updated, err := updateParent(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[path[0]] = updated
+		return node, nil
+	case []interface{}:
+		index, err := arrayIndex(path[0], len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := updateParent(node[index], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = updated
+		return node, nil
+	default:
+		return nil, fmt.Errorf("cannot traverse into %q", path[0])
+	}
+}
+
+func pointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updateParent(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[key] = value
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(key, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("cannot add member %q to a scalar", key)
+		}
+	})
+}
+
+func pointerRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the whole document")
+	}
+	var removed interface{}
+	doc, err := updateParent(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("path member %q not found", key)
+			}
+			removed = value
+			delete(node, key)
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(key, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = node[index]
+			return append(node[:index:index], node[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot remove member %q from a scalar", key)
+		}
+	})
+	return removed, doc, err
+}
+
+func deepCopy(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var copied interface{}
+	err = json.Unmarshal(data, &copied)
+	return copied, err
+}
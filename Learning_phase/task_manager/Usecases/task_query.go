@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"task_manager/Domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrInvalidQuery = domain.NewError(domain.ErrValidation, "invalid query")
+
+const (
+	defaultTaskLimit = 50
+	maxTaskLimit     = 200
+)
+
+var sortableTaskFields = map[string]bool{
+	"id":       true,
+	"due_date": true,
+	"title":    true,
+	"status":   true,
+}
+
+type taskCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v,omitempty"`
+	ID    string `json:"id"`
+}
+
+func normalizeTaskQuery(query domain.TaskQuery) (domain.TaskQuery, error) {
+	if query.SortBy == "" {
+		query.SortBy = "id"
+	}
+	if !sortableTaskFields[query.SortBy] {
+		return query, fmt.Errorf("%w: cannot sort by %q", ErrInvalidQuery, query.SortBy)
+	}
+
+	switch {
+	case query.Limit < 0:
+		return query, fmt.Errorf("%w: limit must not be negative", ErrInvalidQuery)
+	case query.Limit == 0:
+		query.Limit = defaultTaskLimit
+	case query.Limit > maxTaskLimit:
+		query.Limit = maxTaskLimit
+	}
+
+	if query.DueAfter != nil && query.DueBefore != nil && query.DueAfter.After(*query.DueBefore) {
+		return query, fmt.Errorf("%w: due_after must not be later than due_before", ErrInvalidQuery)
+	}
+
+	query.Cursor = nil
+	if query.After != "" {
+		cursor, err := decodeTaskCursor(query.After, query)
+		if err != nil {
+			return query, err
+		}
+		query.Cursor = cursor
+	}
+
+	return query, nil
+}
+
+func sortSpec(query domain.TaskQuery) string {
+	if query.SortDesc {
+		return "-" + query.SortBy
+	}
+	return query.SortBy
+}
+
+func encodeTaskCursor(task domain.Task, query domain.TaskQuery) string {
+	cursor := taskCursor{Sort: sortSpec(query), ID: task.ID.Hex()}
+	switch query.SortBy {
+	case "due_date":
+		cursor.Value = task.DueDate.UTC().Format(time.RFC3339Nano)
+	case "title":
+		cursor.Value = task.Title
+	case "status":
+		cursor.Value = task.Status
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTaskCursor(encoded string, query domain.TaskQuery) (*domain.TaskCursor, error) {
+	invalid := fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalid
+	}
+	var cursor taskCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, invalid
+	}
+	if cursor.Sort != sortSpec(query) {
+		return nil, fmt.Errorf("%w: cursor does not match sort order", ErrInvalidQuery)
+	}
+
+	id, err := primitive.ObjectIDFromHex(cursor.ID)
+	if err != nil {
+		return nil, invalid
+	}
+
+	result := &domain.TaskCursor{ID: id, Value: cursor.Value}
+	if query.SortBy == "due_date" {
+		dueDate, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, invalid
+		}
+		result.Value = dueDate
+	}
+
+	return result, nil
+}
@@ -0,0 +1,267 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"time"
+)
+
+// SetRecurrence makes id the first occurrence of a new series following
+// rule. When id already belongs to a series, the series is split there:
+// earlier occurrences keep the old rule and later open ones, created from
+// it, are moved to the trash.
+func (u *taskUsecase) SetRecurrence(ctx context.Context, actor domain.Actor, id string, rule domain.RecurrenceRule, version int64) (domain.Task, error) {
+	existing, actor, err := u.loadEdgeTarget(ctx, actor, id, version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	rule = rule.Normalize()
+	if err := validateRecurrence(rule, existing.DueDate).Err(); err != nil {
+		return domain.Task{}, err
+	}
+
+	later, err := u.laterOccurrences(ctx, existing)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	updated, err := u.startSeries(ctx, actor, existing, rule)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if err := u.trashOccurrences(ctx, actor, later); err != nil {
+		return domain.Task{}, err
+	}
+	return updated, nil
+}
+
+// StopRecurrence ends the series at id, moving its later open occurrences
+// to the trash.
+func (u *taskUsecase) StopRecurrence(ctx context.Context, actor domain.Actor, id string, version int64) (domain.Task, error) {
+	existing, actor, err := u.loadEdgeTarget(ctx, actor, id, version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if existing.Recurrence == nil {
+		return domain.Task{}, domain.NotFound("recurrence")
+	}
+
+	later, err := u.laterOccurrences(ctx, existing)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	updated, err := u.taskRepo.SetRecurrence(ctx, id, nil, existing.Version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskRecurrence, domain.AuditTargetTask, id, existing, updated)
+	if err := u.trashOccurrences(ctx, actor, later); err != nil {
+		return domain.Task{}, err
+	}
+	return updated, nil
+}
+
+func (u *taskUsecase) startSeries(ctx context.Context, actor domain.Actor, task domain.Task, rule domain.RecurrenceRule) (domain.Task, error) {
+	id := task.ID.Hex()
+	updated, err := u.taskRepo.SetRecurrence(ctx, id, &domain.Recurrence{
+		RecurrenceRule: rule,
+		Start:          task.DueDate.UTC(),
+		SeriesID:       id,
+		Occurrence:     1,
+		Template:       domain.NewSeriesTemplate(task),
+	}, task.Version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskRecurrence, domain.AuditTargetTask, id, task, updated)
+	if updated.Status == domain.StatusDone {
+		u.continueSeries(ctx, actor, domain.Task{}, updated)
+	}
+	return updated, nil
+}
+
+func errSeriesPartiallyUpdated(failed []string) error {
+	return &domain.Error{
+		Kind:    domain.ErrConflict,
+		Message: "the edit was saved but some occurrences of the series could not be updated",
+		Details: map[string]interface{}{"code": "series_partially_updated", "failed": failed},
+	}
+}
+
+// applyToFuture carries an edit of one occurrence over to the rest of its
+// series: the later open occurrences get the same changes, a moved due date
+// moves them and the series start by the same amount, and the template for
+// occurrences not created yet is updated. Status is never carried over.
+//
+// The edited occurrence's template is updated first, so occurrences created
+// from now on follow the edit whatever happens to the rest. Every occurrence
+// is then updated in one write conditioned on the version read; the ones
+// that changed concurrently or failed are reported rather than overwritten.
+func (u *taskUsecase) applyToFuture(ctx context.Context, actor domain.Actor, existing, updated domain.Task) (domain.Task, error) {
+	if updated.Recurrence == nil {
+		return updated, nil
+	}
+	later, err := u.laterOccurrences(ctx, existing)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	changes := taskChanges(existing, updated)
+	changes.Status, changes.StatusHistory = nil, nil
+	shift := updated.DueDate.Sub(existing.DueDate)
+	template := domain.NewSeriesTemplate(updated)
+
+	updated, err = u.updateOccurrence(ctx, actor, updated, domain.TaskPatch{}, template, shift)
+	if err != nil {
+		failed := []string{existing.ID.Hex()}
+		for _, task := range later {
+			failed = append(failed, task.ID.Hex())
+		}
+		log.Printf("recurrence: updating the template of task %s: %v", existing.ID.Hex(), err)
+		return domain.Task{}, errSeriesPartiallyUpdated(failed)
+	}
+
+	var failed []string
+	for _, task := range later {
+		if _, err := u.updateOccurrence(ctx, actor, task, changes, template, shift); err != nil {
+			log.Printf("recurrence: carrying the edit of task %s over to %s: %v", existing.ID.Hex(), task.ID.Hex(), err)
+			failed = append(failed, task.ID.Hex())
+		}
+	}
+	if len(failed) > 0 {
+		return domain.Task{}, errSeriesPartiallyUpdated(failed)
+	}
+	return updated, nil
+}
+
+func (u *taskUsecase) updateOccurrence(ctx context.Context, actor domain.Actor, task domain.Task, patch domain.TaskPatch, template domain.SeriesTemplate, shift time.Duration) (domain.Task, error) {
+	id := task.ID.Hex()
+	if patch.DueDate != nil {
+		due := task.DueDate.Add(shift)
+		patch.DueDate = &due
+	}
+	recurrence := *task.Recurrence
+	recurrence.Template = template
+	recurrence.Start = recurrence.Start.Add(shift)
+	patch.Recurrence = &recurrence
+
+	updated, err := u.taskRepo.Patch(ctx, id, patch, task.Version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskRecurrence, domain.AuditTargetTask, id, task, updated)
+	return updated, nil
+}
+
+// trashOccurrences moves the later occurrences of a series that was just
+// restarted or stopped to the trash. The series change is already saved, so
+// the occurrences that could not be trashed are reported, not retried.
+func (u *taskUsecase) trashOccurrences(ctx context.Context, actor domain.Actor, occurrences []domain.Task) error {
+	var failed []string
+	for _, occurrence := range occurrences {
+		id := occurrence.ID.Hex()
+		if err := u.taskRepo.Delete(ctx, id, actor.UserID, occurrence.Version); err != nil {
+			log.Printf("recurrence: trashing occurrence %s: %v", id, err)
+			failed = append(failed, id)
+			continue
+		}
+		u.auditUsecase.Record(ctx, actor, domain.AuditTaskDelete, domain.AuditTargetTask, id, occurrence, nil)
+	}
+	if len(failed) > 0 {
+		return errSeriesPartiallyUpdated(failed)
+	}
+	return nil
+}
+
+// laterOccurrences lists the occurrences created after task in its series
+// that are still open.
+func (u *taskUsecase) laterOccurrences(ctx context.Context, task domain.Task) ([]domain.Task, error) {
+	if task.Recurrence == nil {
+		return nil, nil
+	}
+	series, err := u.taskRepo.GetAll(ctx, domain.TaskQuery{SeriesID: task.Recurrence.SeriesID})
+	if err != nil {
+		return nil, err
+	}
+
+	var later []domain.Task
+	for _, occurrence := range series {
+		if occurrence.Recurrence.Occurrence <= task.Recurrence.Occurrence {
+			continue
+		}
+		if occurrence.Status != domain.StatusDone && occurrence.Status != domain.StatusArchived {
+			later = append(later, occurrence)
+		}
+	}
+	return later, nil
+}
+
+// continueSeries creates the next occurrence as soon as a recurring task is
+// completed. A failure is only logged: the write that completed the task has
+// already succeeded, and the scheduler retries on its next run.
+func (u *taskUsecase) continueSeries(ctx context.Context, actor domain.Actor, existing, updated domain.Task) {
+	if updated.Recurrence == nil || updated.Status != domain.StatusDone || existing.Status == domain.StatusDone {
+		return
+	}
+	occurrences := occurrenceCreator{taskRepo: u.taskRepo, auditUsecase: u.auditUsecase}
+	if _, err := occurrences.createNext(ctx, actor, updated, time.Now()); err != nil {
+		log.Printf("recurrence: creating the occurrence after task %s: %v", updated.ID.Hex(), err)
+	}
+}
+
+type occurrenceCreator struct {
+	taskRepo     repositories.TaskRepository
+	auditUsecase AuditUsecase
+}
+
+// createNext materializes the occurrence that follows task, at most once per
+// task. Its due date is the first one in the series after both task's
+// position and now, so occurrences missed while a task sat overdue are
+// skipped rather than created in a burst, and moving one occurrence's due
+// date does not shift the rest. It reports whether an occurrence was created.
+func (c occurrenceCreator) createNext(ctx context.Context, actor domain.Actor, task domain.Task, now time.Time) (bool, error) {
+	recurrence := task.Recurrence
+	if recurrence == nil || recurrence.NextCreated {
+		return false, nil
+	}
+	id := task.ID.Hex()
+	claimed, err := c.taskRepo.MarkNextOccurrence(ctx, id, true)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	due, occurrence, ok := recurrence.Next(recurrence.Start, recurrence.Occurrence, now)
+	if !ok {
+		// The series has ended; the flag stays set so the task is not
+		// looked at again.
+		return false, nil
+	}
+
+	next := *recurrence
+	next.Occurrence, next.NextCreated = occurrence, false
+	template := recurrence.Template
+	created, err := c.taskRepo.Create(ctx, domain.Task{
+		Title:           template.Title,
+		Description:     template.Description,
+		DueDate:         due,
+		Status:          domain.StatusTodo,
+		Priority:        template.Priority,
+		EstimateMinutes: template.EstimateMinutes,
+		StatusHistory:   []domain.StatusChange{{Status: domain.StatusTodo, EnteredAt: now, By: actor.UserID}},
+		CreatedBy:       task.CreatedBy,
+		AssigneeID:      template.AssigneeID,
+		ProjectID:       task.ProjectID,
+		ParentID:        task.ParentID,
+		Labels:          task.Labels,
+		Recurrence:      &next,
+	})
+	if err != nil {
+		if _, markErr := c.taskRepo.MarkNextOccurrence(context.WithoutCancel(ctx), id, false); markErr != nil {
+			log.Printf("recurrence: releasing task %s: %v", id, markErr)
+		}
+		return false, err
+	}
+	c.auditUsecase.Record(ctx, actor, domain.AuditTaskCreate, domain.AuditTargetTask, created.ID.Hex(), nil, created)
+	return true, nil
+}
@@ -1,42 +1,408 @@
 package usecases
 
 import (
+	"context"
+	"strings"
 	"task_manager/Domain"
 	"task_manager/Repositories"
+	"time"
+)
+
+var (
+	ErrForbidden     = domain.NewError(domain.ErrForbidden, "not allowed to access this task")
+	ErrInvalidStatus = &domain.Error{
+		Kind:    domain.ErrValidation,
+		Message: "invalid status, expected one of " + strings.Join(domain.TaskStatuses, ", "),
+		Details: map[string]interface{}{"code": "invalid_status"},
+	}
 )
 
 type TaskUsecase interface {
-	GetAllTasks() ([]domain.Task, error)
-	GetTaskByID(id string) (domain.Task, error)
-	CreateTask(task domain.Task) (domain.Task, error)
-	UpdateTask(id string, task domain.Task) (domain.Task, error)
-	DeleteTask(id string) error
+	GetAllTasks(ctx context.Context, actor domain.Actor, query domain.TaskQuery) (domain.TaskPage, error)
+	GetTaskByID(ctx context.Context, actor domain.Actor, id string) (domain.Task, error)
+	CreateTask(ctx context.Context, actor domain.Actor, task domain.Task) (domain.Task, error)
+	UpdateTask(ctx context.Context, actor domain.Actor, id string, task domain.Task, version int64, scope string) (domain.Task, error)
+	PatchTask(ctx context.Context, actor domain.Actor, id string, format domain.PatchFormat, document []byte, version int64, scope string) (domain.Task, error)
+	TransitionTask(ctx context.Context, actor domain.Actor, id, status string, version int64) (domain.Task, error)
+	DeleteTask(ctx context.Context, actor domain.Actor, id string, version int64) error
+	GetDeletedTasks(ctx context.Context, actor domain.Actor, query domain.TaskQuery) (domain.TaskPage, error)
+	RestoreTask(ctx context.Context, actor domain.Actor, id string) (domain.Task, error)
+	SetParent(ctx context.Context, actor domain.Actor, id, parentID string, version int64) (domain.Task, error)
+	AddBlocker(ctx context.Context, actor domain.Actor, id, blockerID string, version int64) (domain.Task, error)
+	RemoveBlocker(ctx context.Context, actor domain.Actor, id, blockerID string, version int64) (domain.Task, error)
+	GetTaskGraph(ctx context.Context, actor domain.Actor, id string) (domain.TaskGraph, error)
+	AttachLabel(ctx context.Context, actor domain.Actor, id, label string, version int64) (domain.Task, error)
+	DetachLabel(ctx context.Context, actor domain.Actor, id, label string, version int64) (domain.Task, error)
+	SetRecurrence(ctx context.Context, actor domain.Actor, id string, rule domain.RecurrenceRule, version int64) (domain.Task, error)
+	StopRecurrence(ctx context.Context, actor domain.Actor, id string, version int64) (domain.Task, error)
 }
 
 type taskUsecase struct {
-	taskRepo repositories.TaskRepository
+	taskRepo     repositories.TaskRepository
+	labelRepo    repositories.LabelRepository
+	projectRepo  repositories.ProjectRepository
+	auditUsecase AuditUsecase
+	workflow     domain.Workflow
+}
+
+func NewTaskUsecase(taskRepo repositories.TaskRepository, labelRepo repositories.LabelRepository, projectRepo repositories.ProjectRepository, auditUsecase AuditUsecase, workflow domain.Workflow) TaskUsecase {
+	return &taskUsecase{taskRepo: taskRepo, labelRepo: labelRepo, projectRepo: projectRepo, auditUsecase: auditUsecase, workflow: workflow}
+}
+
+// GetAllTasks lists the actor's tasks. Outside a project route only tasks
+// that belong to no project are listed, unless the query names a project the
+// actor is a member of.
+func (u *taskUsecase) GetAllTasks(ctx context.Context, actor domain.Actor, query domain.TaskQuery) (domain.TaskPage, error) {
+	query, err := normalizeTaskQuery(query)
+	if err != nil {
+		return domain.TaskPage{}, err
+	}
+	if actor.ProjectID == "" && query.ProjectID != "" {
+		if actor, err = actorInProject(ctx, u.projectRepo, actor, query.ProjectID, "project"); err != nil {
+			return domain.TaskPage{}, err
+		}
+	}
+	if !actor.Has(domain.PermTasksReadAny) {
+		if !actor.Has(domain.PermTasksReadOwn) {
+			return domain.TaskPage{}, ErrForbidden
+		}
+		query.OwnerID = actor.UserID
+	}
+	query.ProjectID = actor.ProjectID
+	query.NoProject = actor.ProjectID == ""
+	query.Deleted = false
+	return u.listTasks(ctx, query)
+}
+
+func (u *taskUsecase) listTasks(ctx context.Context, query domain.TaskQuery) (domain.TaskPage, error) {
+	limit := query.Limit
+	query.Limit++
+	tasks, err := u.taskRepo.GetAll(ctx, query)
+	if err != nil {
+		return domain.TaskPage{}, err
+	}
+
+	page := domain.TaskPage{Tasks: tasks}
+	if len(tasks) > limit {
+		page.Tasks = tasks[:limit]
+		page.NextCursor = encodeTaskCursor(page.Tasks[limit-1], query)
+	}
+	return page, nil
+}
+
+func (u *taskUsecase) GetTaskByID(ctx context.Context, actor domain.Actor, id string) (domain.Task, error) {
+	task, actor, err := u.getInScope(ctx, actor, id)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if !isPermitted(actor, task, domain.PermTasksReadAny, domain.PermTasksReadOwn) {
+		return domain.Task{}, ErrForbidden
+	}
+	return task, nil
+}
+
+func (u *taskUsecase) CreateTask(ctx context.Context, actor domain.Actor, task domain.Task) (domain.Task, error) {
+	task.CreatedBy = actor.UserID
+	task.ProjectID = actor.ProjectID
+	task.ParentID, task.BlockedBy, task.Labels = "", nil, nil
+	task.DeletedAt, task.DeletedBy = nil, ""
+	if task.AssigneeID == "" {
+		task.AssigneeID = actor.UserID
+	}
+	if !isPermitted(actor, task, domain.PermTasksWriteAny, domain.PermTasksWriteOwn) {
+		return domain.Task{}, ErrForbidden
+	}
+	if task.AssigneeID != actor.UserID && !actor.Has(domain.PermTasksWriteAny) {
+		return domain.Task{}, ErrForbidden
+	}
+
+	if task.Status == "" {
+		task.Status = domain.StatusTodo
+	}
+	if task.Priority == "" {
+		task.Priority = domain.PriorityMedium
+	}
+	task.Title = strings.TrimSpace(task.Title)
+	errs := validateTask(task, nil)
+	var rule *domain.RecurrenceRule
+	if task.Recurrence != nil {
+		normalized := task.Recurrence.RecurrenceRule.Normalize()
+		errs = append(errs, validateRecurrence(normalized, task.DueDate)...)
+		rule, task.Recurrence = &normalized, nil
+	}
+	if err := errs.Err(); err != nil {
+		return domain.Task{}, err
+	}
+	task.StatusHistory = []domain.StatusChange{{Status: task.Status, EnteredAt: time.Now(), By: actor.UserID}}
+
+	created, err := u.taskRepo.Create(ctx, task)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskCreate, domain.AuditTargetTask, created.ID.Hex(), nil, created)
+	if rule != nil {
+		return u.startSeries(ctx, actor, created, *rule)
+	}
+	return created, nil
+}
+
+func (u *taskUsecase) UpdateTask(ctx context.Context, actor domain.Actor, id string, task domain.Task, version int64, scope string) (domain.Task, error) {
+	existing, actor, err := u.loadVersion(ctx, actor, id, version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if !isPermitted(actor, existing, domain.PermTasksWriteAny, domain.PermTasksWriteOwn) {
+		return domain.Task{}, ErrForbidden
+	}
+
+	task.CreatedBy = existing.CreatedBy
+	task.ProjectID = existing.ProjectID
+	task.ParentID, task.BlockedBy = existing.ParentID, existing.BlockedBy
+	task.Labels, task.Recurrence = existing.Labels, existing.Recurrence
+	task.DeletedAt, task.DeletedBy = existing.DeletedAt, existing.DeletedBy
+	if task.Priority == "" {
+		task.Priority = existing.Priority
+	}
+	if task.AssigneeID == "" {
+		task.AssigneeID = existing.AssigneeID
+	}
+	if task.AssigneeID != existing.AssigneeID && task.AssigneeID != actor.UserID && !actor.Has(domain.PermTasksWriteAny) {
+		return domain.Task{}, ErrForbidden
+	}
+
+	task.StatusHistory = existing.StatusHistory
+	if task.Status == "" {
+		task.Status = existing.Status
+	}
+	task.Title = strings.TrimSpace(task.Title)
+	if err := validateTask(task, &existing).Err(); err != nil {
+		return domain.Task{}, err
+	}
+	if err := u.applyTransition(ctx, actor, existing, &task); err != nil {
+		return domain.Task{}, err
+	}
+
+	updated, err := u.save(ctx, actor, domain.AuditTaskUpdate, existing, task)
+	if err != nil || scope != domain.EditScopeFuture {
+		return updated, err
+	}
+	return u.applyToFuture(ctx, actor, existing, updated)
 }
 
-func NewTaskUsecase(taskRepo repositories.TaskRepository) TaskUsecase {
-	return &taskUsecase{taskRepo: taskRepo}
+func (u *taskUsecase) PatchTask(ctx context.Context, actor domain.Actor, id string, format domain.PatchFormat, document []byte, version int64, scope string) (domain.Task, error) {
+	existing, actor, err := u.loadVersion(ctx, actor, id, version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if !isPermitted(actor, existing, domain.PermTasksWriteAny, domain.PermTasksWriteOwn) {
+		return domain.Task{}, ErrForbidden
+	}
+
+	task, err := patchTaskDocument(existing, format, document)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	// The series template is not part of the JSON document.
+	task.Recurrence = existing.Recurrence
+	task.Title = strings.TrimSpace(task.Title)
+	if err := validateTask(task, &existing).Err(); err != nil {
+		return domain.Task{}, err
+	}
+	if task.AssigneeID != existing.AssigneeID && task.AssigneeID != actor.UserID && !actor.Has(domain.PermTasksWriteAny) {
+		return domain.Task{}, ErrForbidden
+	}
+	if err := u.applyTransition(ctx, actor, existing, &task); err != nil {
+		return domain.Task{}, err
+	}
+
+	patch := taskChanges(existing, task)
+	if patch.IsEmpty() {
+		return existing, nil
+	}
+
+	updated, err := u.taskRepo.Patch(ctx, id, patch, existing.Version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskPatch, domain.AuditTargetTask, id, existing, updated)
+	u.continueSeries(ctx, actor, existing, updated)
+	if scope != domain.EditScopeFuture {
+		return updated, nil
+	}
+	return u.applyToFuture(ctx, actor, existing, updated)
+}
+
+// taskChanges lists the editable fields of task that differ from existing.
+func taskChanges(existing, task domain.Task) domain.TaskPatch {
+	var patch domain.TaskPatch
+	if task.Title != existing.Title {
+		patch.Title = &task.Title
+	}
+	if task.Description != existing.Description {
+		patch.Description = &task.Description
+	}
+	if !task.DueDate.Equal(existing.DueDate) {
+		patch.DueDate = &task.DueDate
+	}
+	if task.Status != existing.Status {
+		patch.Status = &task.Status
+		patch.StatusHistory = task.StatusHistory
+	}
+	if task.AssigneeID != existing.AssigneeID {
+		patch.AssigneeID = &task.AssigneeID
+	}
+	if task.Priority != existing.Priority {
+		patch.Priority = &task.Priority
+	}
+	if task.EstimateMinutes != existing.EstimateMinutes {
+		patch.EstimateMinutes = &task.EstimateMinutes
+	}
+	return patch
+}
+
+func (u *taskUsecase) TransitionTask(ctx context.Context, actor domain.Actor, id, status string, version int64) (domain.Task, error) {
+	existing, actor, err := u.loadVersion(ctx, actor, id, version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if !isPermitted(actor, existing, domain.PermTasksWriteAny, domain.PermTasksWriteOwn) {
+		return domain.Task{}, ErrForbidden
+	}
+
+	task := existing
+	task.Status = status
+	if task.Status == existing.Status {
+		return domain.Task{}, &domain.TransitionError{From: existing.Status, To: status}
+	}
+	if err := u.applyTransition(ctx, actor, existing, &task); err != nil {
+		return domain.Task{}, err
+	}
+
+	return u.save(ctx, actor, domain.AuditTaskTransition, existing, task)
+}
+
+func (u *taskUsecase) save(ctx context.Context, actor domain.Actor, action string, existing, task domain.Task) (domain.Task, error) {
+	id := existing.ID.Hex()
+	updated, err := u.taskRepo.Update(ctx, id, task, existing.Version)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, action, domain.AuditTargetTask, id, existing, updated)
+	u.continueSeries(ctx, actor, existing, updated)
+	return updated, nil
+}
+
+// applyTransition validates a status change against the workflow and records
+// when the new status was entered. Tasks still carrying a status from before
+// the workflow existed may move to any known status. A task cannot be marked
+// done while any of its blockers is still open.
+func (u *taskUsecase) applyTransition(ctx context.Context, actor domain.Actor, existing domain.Task, task *domain.Task) error {
+	if task.Status == existing.Status {
+		return nil
+	}
+	if !domain.IsTaskStatus(task.Status) {
+		return ErrInvalidStatus
+	}
+	if domain.IsTaskStatus(existing.Status) && !u.workflow.CanTransition(existing.Status, task.Status) {
+		return &domain.TransitionError{From: existing.Status, To: task.Status}
+	}
+	if task.Status == domain.StatusDone {
+		open, err := u.openBlockers(ctx, existing)
+		if err != nil {
+			return err
+		}
+		if len(open) > 0 {
+			return &domain.BlockedError{Blockers: open}
+		}
+	}
+
+	task.StatusHistory = append(append([]domain.StatusChange{}, existing.StatusHistory...), domain.StatusChange{
+		Status:    task.Status,
+		EnteredAt: time.Now(),
+		By:        actor.UserID,
+	})
+	return nil
+}
+
+func (u *taskUsecase) DeleteTask(ctx context.Context, actor domain.Actor, id string, version int64) error {
+	existing, actor, err := u.loadVersion(ctx, actor, id, version)
+	if err != nil {
+		return err
+	}
+	if !isPermitted(actor, existing, domain.PermTasksDeleteAny, domain.PermTasksDeleteOwn) {
+		return ErrForbidden
+	}
+
+	if err := u.taskRepo.Delete(ctx, id, actor.UserID, existing.Version); err != nil {
+		return err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskDelete, domain.AuditTargetTask, id, existing, nil)
+	return nil
 }
 
-func (u *taskUsecase) GetAllTasks() ([]domain.Task, error) {
-	return u.taskRepo.GetAll()
+func (u *taskUsecase) GetDeletedTasks(ctx context.Context, actor domain.Actor, query domain.TaskQuery) (domain.TaskPage, error) {
+	if !actor.Has(domain.PermTasksTrash) {
+		return domain.TaskPage{}, ErrForbidden
+	}
+	query, err := normalizeTaskQuery(query)
+	if err != nil {
+		return domain.TaskPage{}, err
+	}
+	query.Deleted = true
+	return u.listTasks(ctx, query)
+}
+
+func (u *taskUsecase) RestoreTask(ctx context.Context, actor domain.Actor, id string) (domain.Task, error) {
+	if !actor.Has(domain.PermTasksTrash) {
+		return domain.Task{}, ErrForbidden
+	}
+
+	restored, err := u.taskRepo.Restore(ctx, id)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTaskRestore, domain.AuditTargetTask, id, nil, restored)
+	return restored, nil
 }
 
-func (u *taskUsecase) GetTaskByID(id string) (domain.Task, error) {
-	return u.taskRepo.GetByID(id)
+// getInScope fetches a task together with the actor as it acts on it: with
+// the permissions of their role in the task's project, if it has one. Tasks
+// outside the actor's project, or of projects the actor does not belong to,
+// are not found.
+func (u *taskUsecase) getInScope(ctx context.Context, actor domain.Actor, id string) (domain.Task, domain.Actor, error) {
+	task, err := u.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Task{}, domain.Actor{}, err
+	}
+	actor, err = actorInProject(ctx, u.projectRepo, actor, task.ProjectID, "task")
+	if err != nil {
+		return domain.Task{}, domain.Actor{}, err
+	}
+	return task, actor, nil
 }
 
-func (u *taskUsecase) CreateTask(task domain.Task) (domain.Task, error) {
-	return u.taskRepo.Create(task)
+// loadVersion fetches a task for a read-modify-write cycle. Any version
+// other than domain.AnyVersion is the caller's precondition; the write that
+// follows is always conditioned on the version read here so concurrent
+// edits are not lost.
+func (u *taskUsecase) loadVersion(ctx context.Context, actor domain.Actor, id string, version int64) (domain.Task, domain.Actor, error) {
+	existing, actor, err := u.getInScope(ctx, actor, id)
+	if err != nil {
+		return domain.Task{}, domain.Actor{}, err
+	}
+	if version != domain.AnyVersion && existing.Version != version {
+		return domain.Task{}, domain.Actor{}, domain.ErrVersionMismatch
+	}
+	return existing, actor, nil
 }
 
-func (u *taskUsecase) UpdateTask(id string, task domain.Task) (domain.Task, error) {
-	return u.taskRepo.Update(id, task)
+func isPermitted(actor domain.Actor, task domain.Task, anyPermission, ownPermission string) bool {
+	if actor.Has(anyPermission) {
+		return true
+	}
+	return actor.Has(ownPermission) && isTaskOwner(actor, task)
 }
 
-func (u *taskUsecase) DeleteTask(id string) error {
-	return u.taskRepo.Delete(id)
+func isTaskOwner(actor domain.Actor, task domain.Task) bool {
+	return actor.UserID != "" && (task.CreatedBy == actor.UserID || task.AssigneeID == actor.UserID)
 }
@@ -0,0 +1,48 @@
+package usecases
+
+import (
+	"context"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTaskWritesIgnoreDeletion(t *testing.T) {
+	repo := repositories.NewMemoryTaskRepository()
+	u := NewTaskUsecase(repo, nil, nil, NewAuditUsecase(repositories.NewMemoryAuditRepository()), domain.DefaultWorkflow)
+	actor := domain.Actor{UserID: primitive.NewObjectID().Hex(), Permissions: []string{domain.PermTasksWriteOwn, domain.PermTasksReadOwn}}
+	deletedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	due := time.Now().Add(24 * time.Hour).UTC()
+
+	created, err := u.CreateTask(context.Background(), actor, domain.Task{
+		Title: "task", DueDate: due, DeletedAt: &deletedAt, DeletedBy: "someone",
+	})
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	id := created.ID.Hex()
+	stored, err := repo.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("created task is not readable: %v", err)
+	}
+	if stored.DeletedAt != nil || stored.DeletedBy != "" {
+		t.Errorf("CreateTask() stored deleted_at = %v, deleted_by = %q", stored.DeletedAt, stored.DeletedBy)
+	}
+
+	_, err = u.UpdateTask(context.Background(), actor, id, domain.Task{
+		Title: "renamed", DueDate: due, DeletedAt: &deletedAt, DeletedBy: "someone",
+	}, domain.AnyVersion, domain.EditScopeThis)
+	if err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	stored, err = repo.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("updated task is not readable: %v", err)
+	}
+	if stored.Title != "renamed" || stored.DeletedAt != nil || stored.DeletedBy != "" {
+		t.Errorf("UpdateTask() stored title = %q, deleted_at = %v, deleted_by = %q", stored.Title, stored.DeletedAt, stored.DeletedBy)
+	}
+}
@@ -0,0 +1,281 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"task_manager/Domain"
+	"task_manager/Repositories"
+	"time"
+)
+
+const (
+	maxTimeLogDuration = 24 * time.Hour
+	// timeLogClockSkew tolerates clients whose clocks run slightly ahead.
+	timeLogClockSkew = time.Minute
+)
+
+var ErrTimeLogForbidden = domain.NewError(domain.ErrForbidden, "only the owner can delete this time log")
+
+func errTimerRunning(running domain.TimeLog) error {
+	return &domain.Error{
+		Kind:    domain.ErrConflict,
+		Message: "a timer is already running; stop it first",
+		Details: map[string]interface{}{"code": "timer_running", "task_id": running.TaskID, "timelog_id": running.ID.Hex()},
+	}
+}
+
+type TimeLogUsecase interface {
+	GetTaskTime(ctx context.Context, actor domain.Actor, taskID string) (domain.TaskTimeReport, error)
+	LogTime(ctx context.Context, actor domain.Actor, taskID string, req domain.TimeLogRequest) (domain.TimeLog, error)
+	DeleteTimeLog(ctx context.Context, actor domain.Actor, taskID, logID string) error
+	StartTimer(ctx context.Context, actor domain.Actor, taskID, note string) (domain.TimeLog, error)
+	StopTimer(ctx context.Context, actor domain.Actor, taskID, note string) (domain.TimeLog, error)
+	GetRunningTimer(ctx context.Context, actor domain.Actor) (domain.TimeLog, error)
+	GetUserTime(ctx context.Context, actor domain.Actor, username string, from, to *time.Time) (domain.UserTimeReport, error)
+}
+
+type timeLogUsecase struct {
+	timeLogRepo  repositories.TimeLogRepository
+	taskRepo     repositories.TaskRepository
+	userRepo     repositories.UserRepository
+	projectRepo  repositories.ProjectRepository
+	auditUsecase AuditUsecase
+}
+
+func NewTimeLogUsecase(timeLogRepo repositories.TimeLogRepository, taskRepo repositories.TaskRepository, userRepo repositories.UserRepository, projectRepo repositories.ProjectRepository, auditUsecase AuditUsecase) TimeLogUsecase {
+	return &timeLogUsecase{timeLogRepo: timeLogRepo, taskRepo: taskRepo, userRepo: userRepo, projectRepo: projectRepo, auditUsecase: auditUsecase}
+}
+
+func (u *timeLogUsecase) GetTaskTime(ctx context.Context, actor domain.Actor, taskID string) (domain.TaskTimeReport, error) {
+	task, err := u.checkTask(ctx, actor, taskID, domain.PermTasksReadAny, domain.PermTasksReadOwn)
+	if err != nil {
+		return domain.TaskTimeReport{}, err
+	}
+
+	logs, err := u.timeLogRepo.GetByTask(ctx, taskID)
+	if err != nil {
+		return domain.TaskTimeReport{}, err
+	}
+	byUser, err := u.timeLogRepo.TotalsByUser(ctx, domain.TimeLogQuery{TaskID: taskID})
+	if err != nil {
+		return domain.TaskTimeReport{}, err
+	}
+
+	report := domain.TaskTimeReport{TaskID: taskID, EstimateMinutes: task.EstimateMinutes, ByUser: byUser, TimeLogs: logs}
+	for _, total := range byUser {
+		report.TotalSeconds += total.TotalSeconds
+	}
+	return report, nil
+}
+
+func (u *timeLogUsecase) LogTime(ctx context.Context, actor domain.Actor, taskID string, req domain.TimeLogRequest) (domain.TimeLog, error) {
+	if _, err := u.checkTask(ctx, actor, taskID, domain.PermTasksWriteAny, domain.PermTasksWriteOwn); err != nil {
+		return domain.TimeLog{}, err
+	}
+
+	now := time.Now().UTC()
+	start, end, errs := resolveTimeSpan(req, now)
+	note := strings.TrimSpace(req.Note)
+	errs = append(errs, validateTimeLogNote(note)...)
+	if err := errs.Err(); err != nil {
+		return domain.TimeLog{}, err
+	}
+
+	created, err := u.timeLogRepo.Create(ctx, domain.TimeLog{
+		TaskID:          taskID,
+		UserID:          actor.UserID,
+		Username:        actor.Username,
+		Start:           start,
+		End:             &end,
+		DurationSeconds: int64(end.Sub(start) / time.Second),
+		Note:            note,
+		CreatedAt:       now,
+	})
+	if err != nil {
+		return domain.TimeLog{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTimeLogCreate, domain.AuditTargetTimeLog, created.ID.Hex(), nil, created)
+	return created, nil
+}
+
+// resolveTimeSpan works out the start and end of a manually logged span
+// from either start and end, or a duration with an optional start.
+func resolveTimeSpan(req domain.TimeLogRequest, now time.Time) (time.Time, time.Time, domain.FieldErrors) {
+	var errs domain.FieldErrors
+
+	var duration time.Duration
+	if req.Duration != "" {
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			errs.Add("duration", "must be a duration such as 1h30m")
+			return time.Time{}, time.Time{}, errs
+		}
+		duration = d
+	}
+
+	var start, end time.Time
+	switch {
+	case req.End != nil && req.Duration != "":
+		errs.Add("end", "give either end or duration, not both")
+		return start, end, errs
+	case req.End != nil && req.Start == nil:
+		errs.Add("start", "is required with end")
+		return start, end, errs
+	case req.End != nil:
+		start, end = req.Start.UTC(), req.End.UTC()
+	case req.Duration == "":
+		errs.Add("duration", "is required unless start and end are given")
+		return start, end, errs
+	case req.Start != nil:
+		start = req.Start.UTC()
+		end = start.Add(duration)
+	default:
+		end = now
+		start = end.Add(-duration)
+	}
+
+	switch span := end.Sub(start); {
+	case span <= 0:
+		errs.Add("duration", "must be positive")
+	case span > maxTimeLogDuration:
+		errs.Add("duration", "must be at most 24h")
+	}
+	if end.After(now.Add(timeLogClockSkew)) {
+		errs.Add("end", "must not be in the future")
+	}
+	return start, end, errs
+}
+
+func (u *timeLogUsecase) DeleteTimeLog(ctx context.Context, actor domain.Actor, taskID, logID string) error {
+	if _, err := u.checkTask(ctx, actor, taskID, domain.PermTasksReadAny, domain.PermTasksReadOwn); err != nil {
+		return err
+	}
+
+	existing, err := u.timeLogRepo.GetByID(ctx, logID)
+	if err != nil {
+		return err
+	}
+	if existing.TaskID != taskID {
+		return domain.NotFound("time log")
+	}
+	if existing.UserID != actor.UserID && !actor.Has(domain.PermTimeLogsManage) {
+		return ErrTimeLogForbidden
+	}
+
+	if err := u.timeLogRepo.Delete(ctx, logID); err != nil {
+		return err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTimeLogDelete, domain.AuditTargetTimeLog, logID, existing, nil)
+	return nil
+}
+
+// StartTimer starts the caller's timer on a task. Each user can have only
+// one running timer, across all tasks.
+func (u *timeLogUsecase) StartTimer(ctx context.Context, actor domain.Actor, taskID, note string) (domain.TimeLog, error) {
+	if _, err := u.checkTask(ctx, actor, taskID, domain.PermTasksWriteAny, domain.PermTasksWriteOwn); err != nil {
+		return domain.TimeLog{}, err
+	}
+	note = strings.TrimSpace(note)
+	if err := validateTimeLogNote(note).Err(); err != nil {
+		return domain.TimeLog{}, err
+	}
+
+	now := time.Now().UTC()
+	created, err := u.timeLogRepo.Create(ctx, domain.TimeLog{
+		TaskID:    taskID,
+		UserID:    actor.UserID,
+		Username:  actor.Username,
+		Start:     now,
+		Note:      note,
+		Running:   true,
+		CreatedAt: now,
+	})
+	if errors.Is(err, domain.ErrTimerRunning) {
+		if running, runningErr := u.timeLogRepo.GetRunning(ctx, actor.UserID); runningErr == nil {
+			return domain.TimeLog{}, errTimerRunning(running)
+		}
+	}
+	if err != nil {
+		return domain.TimeLog{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTimeLogCreate, domain.AuditTargetTimeLog, created.ID.Hex(), nil, created)
+	return created, nil
+}
+
+// StopTimer stops the caller's running timer on a task. A note given here
+// replaces the one given at start. Stopping works even if the task has been
+// trashed in the meantime.
+func (u *timeLogUsecase) StopTimer(ctx context.Context, actor domain.Actor, taskID, note string) (domain.TimeLog, error) {
+	running, err := u.timeLogRepo.GetRunning(ctx, actor.UserID)
+	if err != nil {
+		return domain.TimeLog{}, err
+	}
+	if running.TaskID != taskID {
+		return domain.TimeLog{}, domain.NotFound("running timer")
+	}
+
+	note = strings.TrimSpace(note)
+	if note == "" {
+		note = running.Note
+	}
+	if err := validateTimeLogNote(note).Err(); err != nil {
+		return domain.TimeLog{}, err
+	}
+
+	end := time.Now().UTC()
+	stopped, err := u.timeLogRepo.Stop(ctx, running.ID.Hex(), end, int64(end.Sub(running.Start)/time.Second), note)
+	if err != nil {
+		return domain.TimeLog{}, err
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditTimeLogUpdate, domain.AuditTargetTimeLog, stopped.ID.Hex(), running, stopped)
+	return stopped, nil
+}
+
+func (u *timeLogUsecase) GetRunningTimer(ctx context.Context, actor domain.Actor) (domain.TimeLog, error) {
+	return u.timeLogRepo.GetRunning(ctx, actor.UserID)
+}
+
+// GetUserTime totals a user's finished time logs per task, optionally
+// limited to logs starting between from and to. Looking at anyone but
+// yourself needs timelogs:manage.
+func (u *timeLogUsecase) GetUserTime(ctx context.Context, actor domain.Actor, username string, from, to *time.Time) (domain.UserTimeReport, error) {
+	report := domain.UserTimeReport{UserID: actor.UserID, Username: actor.Username, From: from, To: to}
+	if username != "" && username != actor.Username {
+		if !actor.Has(domain.PermTimeLogsManage) {
+			return domain.UserTimeReport{}, domain.Forbidden("Missing permission: " + domain.PermTimeLogsManage)
+		}
+		user, err := u.userRepo.GetByUsername(ctx, username)
+		if err != nil {
+			return domain.UserTimeReport{}, err
+		}
+		report.UserID, report.Username = user.ID.Hex(), user.Username
+	}
+
+	byTask, err := u.timeLogRepo.TotalsByTask(ctx, domain.TimeLogQuery{UserID: report.UserID, From: from, To: to})
+	if err != nil {
+		return domain.UserTimeReport{}, err
+	}
+	report.ByTask = byTask
+	for _, total := range byTask {
+		report.TotalSeconds += total.TotalSeconds
+	}
+	return report, nil
+}
+
+// checkTask makes sure the task exists, is not in the trash and that actor
+// holds one of the given permissions on it, through their project role for
+// project tasks.
+func (u *timeLogUsecase) checkTask(ctx context.Context, actor domain.Actor, taskID string, anyPermission, ownPermission string) (domain.Task, error) {
+	task, err := u.taskRepo.GetByID(ctx, taskID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if actor, err = actorInProject(ctx, u.projectRepo, actor, task.ProjectID, "task"); err != nil {
+		return domain.Task{}, err
+	}
+	if !isPermitted(actor, task, anyPermission, ownPermission) {
+		return domain.Task{}, ErrForbidden
+	}
+	return task, nil
+}
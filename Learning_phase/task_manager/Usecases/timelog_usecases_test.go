@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"reflect"
+	"task_manager/Domain"
+	"testing"
+	"time"
+)
+
+func TestResolveTimeSpan(t *testing.T) {
+	now := time.Date(2027, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(offset time.Duration) *time.Time {
+		v := now.Add(offset)
+		return &v
+	}
+	paris := time.FixedZone("CET", 3600)
+	local := time.Date(2027, 1, 1, 10, 0, 0, 0, paris)
+
+	tests := []struct {
+		name       string
+		req        domain.TimeLogRequest
+		wantStart  time.Time
+		wantEnd    time.Time
+		wantFields []string
+	}{
+		{
+			name:      "duration ending now",
+			req:       domain.TimeLogRequest{Duration: "1h30m"},
+			wantStart: now.Add(-90 * time.Minute),
+			wantEnd:   now,
+		},
+		{
+			name:      "start and duration",
+			req:       domain.TimeLogRequest{Start: at(-3 * time.Hour), Duration: "1h"},
+			wantStart: now.Add(-3 * time.Hour),
+			wantEnd:   now.Add(-2 * time.Hour),
+		},
+		{
+			name:      "start and end",
+			req:       domain.TimeLogRequest{Start: at(-3 * time.Hour), End: at(-time.Hour)},
+			wantStart: now.Add(-3 * time.Hour),
+			wantEnd:   now.Add(-time.Hour),
+		},
+		{
+			name:      "times are stored in UTC",
+			req:       domain.TimeLogRequest{Start: &local, Duration: "30m"},
+			wantStart: time.Date(2027, 1, 1, 9, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2027, 1, 1, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:      "end within the clock skew",
+			req:       domain.TimeLogRequest{Start: at(-time.Hour), End: at(30 * time.Second)},
+			wantStart: now.Add(-time.Hour),
+			wantEnd:   now.Add(30 * time.Second),
+		},
+		{
+			name:      "exactly 24 hours",
+			req:       domain.TimeLogRequest{Duration: "24h"},
+			wantStart: now.Add(-24 * time.Hour),
+			wantEnd:   now,
+		},
+		{
+			name:       "end and duration",
+			req:        domain.TimeLogRequest{Start: at(-time.Hour), End: at(0), Duration: "1h"},
+			wantFields: []string{"end"},
+		},
+		{
+			name:       "end without start",
+			req:        domain.TimeLogRequest{End: at(0)},
+			wantFields: []string{"start"},
+		},
+		{
+			name:       "nothing given",
+			req:        domain.TimeLogRequest{},
+			wantFields: []string{"duration"},
+		},
+		{
+			name:       "unparsable duration",
+			req:        domain.TimeLogRequest{Duration: "an hour"},
+			wantFields: []string{"duration"},
+		},
+		{
+			name:       "zero duration",
+			req:        domain.TimeLogRequest{Duration: "0s"},
+			wantFields: []string{"duration"},
+		},
+		{
+			name:       "negative duration",
+			req:        domain.TimeLogRequest{Duration: "-1h"},
+			wantFields: []string{"duration"},
+		},
+		{
+			name:       "end before start",
+			req:        domain.TimeLogRequest{Start: at(-time.Hour), End: at(-2 * time.Hour)},
+			wantFields: []string{"duration"},
+		},
+		{
+			name:       "longer than 24 hours",
+			req:        domain.TimeLogRequest{Duration: "24h1s"},
+			wantFields: []string{"duration"},
+		},
+		{
+			name:       "ends in the future",
+			req:        domain.TimeLogRequest{Start: at(0), Duration: "2m"},
+			wantFields: []string{"end"},
+		},
+		{
+			name:       "too long and in the future",
+			req:        domain.TimeLogRequest{Start: at(0), Duration: "25h"},
+			wantFields: []string{"duration", "end"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, errs := resolveTimeSpan(tt.req, now)
+
+			var fields []string
+			for _, fe := range errs {
+				fields = append(fields, fe.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Fatalf("resolveTimeSpan() errors = %v, want fields %v", errs, tt.wantFields)
+			}
+			if tt.wantFields != nil {
+				return
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("resolveTimeSpan() = %v, %v; want %v, %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+			if start.Location() != time.UTC || end.Location() != time.UTC {
+				t.Errorf("resolveTimeSpan() = %v, %v; want UTC times", start, end)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"task_manager/Repositories"
+	"time"
+)
+
+// TrashPurger permanently removes soft-deleted tasks once they have been in
+// the trash for longer than the retention period, together with their
+// comments, time logs and sent reminders.
+type TrashPurger struct {
+	taskRepo     repositories.TaskRepository
+	commentRepo  repositories.CommentRepository
+	timeLogRepo  repositories.TimeLogRepository
+	reminderRepo repositories.ReminderRepository
+	retention    time.Duration
+	interval     time.Duration
+}
+
+func NewTrashPurger(taskRepo repositories.TaskRepository, commentRepo repositories.CommentRepository, timeLogRepo repositories.TimeLogRepository, reminderRepo repositories.ReminderRepository, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{taskRepo: taskRepo, commentRepo: commentRepo, timeLogRepo: timeLogRepo, reminderRepo: reminderRepo, retention: retention, interval: interval}
+}
+
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.PurgeOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *TrashPurger) PurgeOnce(ctx context.Context) {
+	purged, err := p.taskRepo.Purge(ctx, time.Now().Add(-p.retention))
+	if err != nil {
+		log.Printf("trash purger: %v", err)
+		return
+	}
+	if len(purged) == 0 {
+		return
+	}
+	log.Printf("trash purger: permanently removed %d task(s)", len(purged))
+
+	// Comments of purged tasks are unreachable either way, since every
+	// comment lookup goes through its task; this only reclaims the space.
+	if _, err := p.commentRepo.DeleteByTasks(ctx, purged); err != nil {
+		log.Printf("trash purger: removing comments: %v", err)
+	}
+	if _, err := p.timeLogRepo.DeleteByTasks(ctx, purged); err != nil {
+		log.Printf("trash purger: removing time logs: %v", err)
+	}
+	if _, err := p.reminderRepo.DeleteByTasks(ctx, purged); err != nil {
+		log.Printf("trash purger: removing reminders: %v", err)
+	}
+}
@@ -1,46 +1,84 @@
 package usecases
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math"
+	"sync"
 	"task_manager/Domain"
 	"task_manager/Infrastructure"
 	"task_manager/Repositories"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrInvalidRefreshToken = domain.NewError(domain.ErrUnauthorized, "invalid refresh token")
+	ErrRefreshTokenReused  = domain.NewError(domain.ErrUnauthorized, "refresh token reuse detected, all sessions have been revoked")
+	ErrLastAdmin           = domain.NewError(domain.ErrConflict, "cannot remove the last admin")
+	ErrInvalidCredentials  = domain.NewError(domain.ErrUnauthorized, "invalid credentials")
 )
 
 type UserUsecase interface {
-	Register(username, password string) (domain.User, error)
-	Login(username, password string) (domain.User, string, error)
-	PromoteUser(username string) error
+	Register(ctx context.Context, username, password string) (domain.User, error)
+	Login(ctx context.Context, username, password, clientIP string) (domain.User, domain.TokenPair, error)
+	RefreshToken(ctx context.Context, refreshToken string) (domain.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	PromoteUser(ctx context.Context, actor domain.Actor, username string) error
+	DemoteUser(ctx context.Context, actor domain.Actor, username string) error
+	AssignRole(ctx context.Context, actor domain.Actor, username, role string) error
+	GetLockout(ctx context.Context, username string) (domain.LockoutStatus, error)
+	UnlockUser(ctx context.Context, actor domain.Actor, username string) error
 }
 
 type userUsecase struct {
-	userRepo        repositories.UserRepository
-	passwordService *infrastructure.PasswordService
-	jwtService      *infrastructure.JWTService
+	userRepo         repositories.UserRepository
+	refreshTokenRepo repositories.RefreshTokenRepository
+	roleUsecase      RoleUsecase
+	auditUsecase     AuditUsecase
+	passwordService  *infrastructure.PasswordService
+	jwtService       *infrastructure.JWTService
+	passwordPolicy   domain.PasswordPolicy
+	lockoutPolicy    domain.LockoutPolicy
+	throttleRepo     repositories.LoginThrottleRepository
+	dummyHashOnce    sync.Once
+	dummyHash        string
 }
 
-func NewUserUsecase(userRepo repositories.UserRepository, passwordService *infrastructure.PasswordService, jwtService *infrastructure.JWTService) UserUsecase {
+func NewUserUsecase(userRepo repositories.UserRepository, refreshTokenRepo repositories.RefreshTokenRepository, throttleRepo repositories.LoginThrottleRepository, roleUsecase RoleUsecase, auditUsecase AuditUsecase, passwordService *infrastructure.PasswordService, jwtService *infrastructure.JWTService, passwordPolicy domain.PasswordPolicy, lockoutPolicy domain.LockoutPolicy) UserUsecase {
 	return &userUsecase{
-		userRepo:        userRepo,
-		passwordService: passwordService,
-		jwtService:      jwtService,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		roleUsecase:      roleUsecase,
+		auditUsecase:     auditUsecase,
+		passwordService:  passwordService,
+		jwtService:       jwtService,
+		passwordPolicy:   passwordPolicy,
+		lockoutPolicy:    lockoutPolicy,
+		throttleRepo:     throttleRepo,
 	}
 }
 
-func (u *userUsecase) Register(username, password string) (domain.User, error) {
-	existing, _ := u.userRepo.GetByUsername(username)
+func (u *userUsecase) Register(ctx context.Context, username, password string) (domain.User, error) {
+	if err := validateCredentials(username, password, u.passwordPolicy).Err(); err != nil {
+		return domain.User{}, err
+	}
+
+	existing, _ := u.userRepo.GetByUsername(ctx, username)
 	if existing.Username != "" {
-		return domain.User{}, errors.New("username already exists")
+		return domain.User{}, domain.Conflict("username already exists")
 	}
 
-	count, err := u.userRepo.CountUsers()
+	count, err := u.userRepo.CountUsers(ctx)
 	if err != nil {
 		return domain.User{}, err
 	}
 
-	role := "user"
+	role := domain.RoleUser
 	if count == 0 {
-		role = "admin"
+		role = domain.RoleAdmin
 	}
 
 	hashedPassword, err := u.passwordService.HashPassword(password)
@@ -54,35 +92,346 @@ func (u *userUsecase) Register(username, password string) (domain.User, error) {
 		Role:     role,
 	}
 
-	createdUser, err := u.userRepo.Create(user)
+	createdUser, err := u.userRepo.Create(ctx, user)
 	if err != nil {
 		return domain.User{}, err
 	}
 
 	createdUser.Password = ""
+	actor := domain.Actor{UserID: createdUser.ID.Hex(), Username: createdUser.Username}
+	u.auditUsecase.Record(ctx, actor, domain.AuditUserRegister, domain.AuditTargetUser, actor.UserID, nil, createdUser)
 	return createdUser, nil
 }
 
-func (u *userUsecase) Login(username, password string) (domain.User, string, error) {
-	user, err := u.userRepo.GetByUsername(username)
+// Login checks a username and password. Repeated failures lock the account,
+// or the client IP, for an exponentially growing period. Unknown usernames
+// are throttled just like real accounts and every rejection looks the same to
+// the client. Only locks are audited, so failed attempts cannot flood the
+// audit log.
+func (u *userUsecase) Login(ctx context.Context, username, password, clientIP string) (domain.User, domain.TokenPair, error) {
+	now := time.Now()
+	wait, err := u.lockedFor(ctx, ipThrottleKey(clientIP), now)
+	if err != nil {
+		return domain.User{}, domain.TokenPair{}, err
+	}
+	if wait > 0 {
+		return domain.User{}, domain.TokenPair{}, errLoginLocked(wait)
+	}
+
+	user, err := u.userRepo.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.User{}, domain.TokenPair{}, u.failUnknownUser(ctx, username, password, clientIP, now)
+	}
 	if err != nil {
-		return domain.User{}, "", errors.New("invalid credentials")
+		return domain.User{}, domain.TokenPair{}, err
+	}
+
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		return domain.User{}, domain.TokenPair{}, errLoginLocked(user.LockedUntil.Sub(now))
 	}
 
 	err = u.passwordService.ComparePassword(user.Password, password)
 	if err != nil {
-		return domain.User{}, "", errors.New("invalid credentials")
+		return domain.User{}, domain.TokenPair{}, u.failLogin(ctx, user, clientIP, now)
+	}
+
+	if user.FailedLogins > 0 || user.LockedUntil != nil {
+		if err := u.userRepo.ResetLoginFailures(ctx, username); err != nil {
+			return domain.User{}, domain.TokenPair{}, err
+		}
 	}
 
-	token, err := u.jwtService.GenerateToken(user.ID.Hex(), user.Username, user.Role)
+	tokens, err := u.issueTokens(ctx, user, primitive.NewObjectID().Hex())
 	if err != nil {
-		return domain.User{}, "", err
+		return domain.User{}, domain.TokenPair{}, err
 	}
 
 	user.Password = ""
-	return user, token, nil
+	return user, tokens, nil
+}
+
+func (u *userUsecase) failLogin(ctx context.Context, user domain.User, clientIP string, now time.Time) error {
+	failures, err := u.userRepo.RecordLoginFailure(ctx, user.Username, now, now.Add(-u.lockoutPolicy.Window))
+	if err != nil {
+		return err
+	}
+	if err := u.failIP(ctx, clientIP, now); err != nil {
+		return err
+	}
+
+	if delay := u.lockoutPolicy.Delay(failures, u.lockoutPolicy.Threshold); delay > 0 {
+		lockedUntil := now.Add(delay).UTC()
+		if err := u.userRepo.LockUntil(ctx, user.Username, lockedUntil); err != nil {
+			return err
+		}
+		u.recordLock(ctx, user, clientIP, "bad_password", failures, lockedUntil)
+	}
+	return ErrInvalidCredentials
+}
+
+func (u *userUsecase) failUnknownUser(ctx context.Context, username, password, clientIP string, now time.Time) error {
+	key := "user:" + username
+	wait, err := u.lockedFor(ctx, key, now)
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return errLoginLocked(wait)
+	}
+
+	// Spend as long as a real password check would.
+	u.dummyHashOnce.Do(func() {
+		u.dummyHash, _ = u.passwordService.HashPassword(primitive.NewObjectID().Hex())
+	})
+	u.passwordService.ComparePassword(u.dummyHash, password)
+
+	failures, lockedUntil, err := u.throttle(ctx, key, now, u.lockoutPolicy.Threshold)
+	if err != nil {
+		return err
+	}
+	if err := u.failIP(ctx, clientIP, now); err != nil {
+		return err
+	}
+	if !lockedUntil.IsZero() {
+		u.recordLock(ctx, domain.User{Username: username}, clientIP, "unknown_user", failures, lockedUntil)
+	}
+	return ErrInvalidCredentials
+}
+
+func (u *userUsecase) failIP(ctx context.Context, clientIP string, now time.Time) error {
+	if clientIP == "" {
+		return nil
+	}
+	failures, lockedUntil, err := u.throttle(ctx, ipThrottleKey(clientIP), now, u.lockoutPolicy.IPThreshold)
+	if err != nil {
+		return err
+	}
+	if !lockedUntil.IsZero() {
+		u.recordLock(ctx, domain.User{}, clientIP, "ip", failures, lockedUntil)
+	}
+	return nil
+}
+
+// lockedFor returns how much longer the throttle key is locked, or zero.
+func (u *userUsecase) lockedFor(ctx context.Context, key string, now time.Time) (time.Duration, error) {
+	lockedUntil, err := u.throttleRepo.LockedUntil(ctx, key)
+	if err != nil || !now.Before(lockedUntil) {
+		return 0, err
+	}
+	return lockedUntil.Sub(now), nil
+}
+
+// throttle records a failed login for a key without a user record, locking
+// it the same way accounts are locked. It returns the new failure count and
+// the end of the lock the failure earned, if any.
+func (u *userUsecase) throttle(ctx context.Context, key string, now time.Time, threshold int) (int, time.Time, error) {
+	window := u.lockoutPolicy.Window
+	failures, err := u.throttleRepo.RecordFailure(ctx, key, now, now.Add(-window), now.Add(window))
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	delay := u.lockoutPolicy.Delay(failures, threshold)
+	if delay <= 0 {
+		return failures, time.Time{}, nil
+	}
+	lockedUntil := now.Add(delay).UTC()
+	if err := u.throttleRepo.LockUntil(ctx, key, lockedUntil); err != nil {
+		return 0, time.Time{}, err
+	}
+	return failures, lockedUntil, nil
+}
+
+// recordLock audits a lock earned by repeated failures. Attempts rejected
+// while locked are not counted or audited, so there is at most one entry per
+// key and lock period.
+func (u *userUsecase) recordLock(ctx context.Context, user domain.User, clientIP, reason string, failures int, lockedUntil time.Time) {
+	var userID string
+	if !user.ID.IsZero() {
+		userID = user.ID.Hex()
+	}
+	u.auditUsecase.Record(ctx, domain.Actor{}, domain.AuditUserLock, domain.AuditTargetUser, userID,
+		nil, map[string]interface{}{"username": user.Username, "reason": reason, "ip": clientIP, "failed_attempts": failures, "locked_until": lockedUntil})
+}
+
+func ipThrottleKey(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+func errLoginLocked(wait time.Duration) error {
+	return &domain.Error{
+		Kind:    domain.ErrTooManyRequests,
+		Message: "too many failed login attempts, try again later",
+		Details: map[string]interface{}{
+			"code":        "login_locked",
+			"retry_after": int(math.Ceil(wait.Seconds())),
+		},
+	}
+}
+
+func (u *userUsecase) RefreshToken(ctx context.Context, refreshToken string) (domain.TokenPair, error) {
+	stored, err := u.refreshTokenRepo.GetByHash(ctx, u.jwtService.HashRefreshToken(refreshToken))
+	if err != nil {
+		return domain.TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	if stored.RevokedAt != nil {
+		return domain.TokenPair{}, u.revokedTokenUsed(ctx, stored)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return domain.TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	revoked, err := u.refreshTokenRepo.Revoke(ctx, stored.ID.Hex(), domain.RevokeRotated)
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+	if !revoked {
+		// Revoked since it was read: by a concurrent refresh or a logout.
+		current, err := u.refreshTokenRepo.GetByHash(ctx, stored.TokenHash)
+		if err != nil {
+			return domain.TokenPair{}, ErrInvalidRefreshToken
+		}
+		return domain.TokenPair{}, u.revokedTokenUsed(ctx, current)
+	}
+
+	user, err := u.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return domain.TokenPair{}, ErrInvalidRefreshToken
+	}
+
+	return u.issueTokens(ctx, user, stored.FamilyID)
+}
+
+// revokedTokenUsed handles a revoked refresh token being presented. Only a
+// rotated token coming back means it was stolen, and then every session of
+// the user is revoked; one revoked by logout is merely invalid.
+func (u *userUsecase) revokedTokenUsed(ctx context.Context, token domain.RefreshToken) error {
+	if token.RevokeReason != domain.RevokeRotated {
+		return ErrInvalidRefreshToken
+	}
+	if err := u.refreshTokenRepo.RevokeAllForUser(ctx, token.UserID, domain.RevokeReused); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
 }
 
-func (u *userUsecase) PromoteUser(username string) error {
-	return u.userRepo.PromoteToAdmin(username)
+func (u *userUsecase) Logout(ctx context.Context, refreshToken string) error {
+	stored, err := u.refreshTokenRepo.GetByHash(ctx, u.jwtService.HashRefreshToken(refreshToken))
+	if err != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	if err := u.refreshTokenRepo.RevokeFamily(ctx, stored.FamilyID, domain.RevokeLogout); err != nil {
+		return err
+	}
+
+	actor := domain.Actor{UserID: stored.UserID}
+	if user, err := u.userRepo.GetByID(ctx, stored.UserID); err == nil {
+		actor.Username = user.Username
+	}
+	u.auditUsecase.Record(ctx, actor, domain.AuditUserLogout, domain.AuditTargetUser, stored.UserID, nil, nil)
+	return nil
+}
+
+func (u *userUsecase) issueTokens(ctx context.Context, user domain.User, familyID string) (domain.TokenPair, error) {
+	permissions, err := u.roleUsecase.Permissions(ctx, user.Role)
+	if err != nil && !errors.Is(err, ErrRoleNotFound) {
+		return domain.TokenPair{}, err
+	}
+
+	accessToken, err := u.jwtService.GenerateToken(user.ID.Hex(), user.Username, user.Role, permissions)
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+
+	refreshToken, err := u.jwtService.GenerateRefreshToken()
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+
+	now := time.Now()
+	_, err = u.refreshTokenRepo.Create(ctx, domain.RefreshToken{
+		UserID:    user.ID.Hex(),
+		FamilyID:  familyID,
+		TokenHash: u.jwtService.HashRefreshToken(refreshToken),
+		CreatedAt: now,
+		ExpiresAt: now.Add(u.jwtService.RefreshTokenTTL()),
+	})
+	if err != nil {
+		return domain.TokenPair{}, err
+	}
+
+	return domain.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(u.jwtService.TokenTTL().Seconds()),
+	}, nil
+}
+
+func (u *userUsecase) PromoteUser(ctx context.Context, actor domain.Actor, username string) error {
+	return u.AssignRole(ctx, actor, username, domain.RoleAdmin)
+}
+
+func (u *userUsecase) DemoteUser(ctx context.Context, actor domain.Actor, username string) error {
+	return u.AssignRole(ctx, actor, username, domain.RoleUser)
+}
+
+func (u *userUsecase) AssignRole(ctx context.Context, actor domain.Actor, username, role string) error {
+	if _, err := u.roleUsecase.GetRole(ctx, role); errors.Is(err, ErrRoleNotFound) {
+		return domain.Validation(fmt.Sprintf("role %q does not exist", role))
+	} else if err != nil {
+		return err
+	}
+
+	user, err := u.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == domain.RoleAdmin && role != domain.RoleAdmin {
+		admins, err := u.userRepo.CountByRole(ctx, domain.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if admins <= 1 {
+			return ErrLastAdmin
+		}
+	}
+
+	if err := u.userRepo.SetRole(ctx, username, role); err != nil {
+		return err
+	}
+
+	u.auditUsecase.Record(ctx, actor, domain.AuditUserRole, domain.AuditTargetUser, user.ID.Hex(),
+		map[string]string{"role": user.Role}, map[string]string{"role": role})
+	return nil
+}
+
+func (u *userUsecase) GetLockout(ctx context.Context, username string) (domain.LockoutStatus, error) {
+	user, err := u.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return domain.LockoutStatus{}, err
+	}
+
+	status := domain.LockoutStatus{Username: user.Username, FailedAttempts: user.FailedLogins}
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		status.LockedUntil = user.LockedUntil
+	}
+	return status, nil
+}
+
+func (u *userUsecase) UnlockUser(ctx context.Context, actor domain.Actor, username string) error {
+	user, err := u.userRepo.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepo.ResetLoginFailures(ctx, username); err != nil {
+		return err
+	}
+
+	u.auditUsecase.Record(ctx, actor, domain.AuditUserUnlock, domain.AuditTargetUser, user.ID.Hex(),
+		map[string]interface{}{"failed_attempts": user.FailedLogins, "locked_until": user.LockedUntil},
+		map[string]interface{}{"failed_attempts": 0, "locked_until": nil})
+	return nil
 }
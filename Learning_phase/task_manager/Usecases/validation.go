@@ -0,0 +1,182 @@
+package usecases
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"task_manager/Domain"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxTitleLength   = 200
+	maxCommentLength = 5000
+	maxNoteLength    = 1000
+	// maxEstimateMinutes is one year of round-the-clock work.
+	maxEstimateMinutes    = 525600
+	maxRecurrenceInterval = 1000
+	maxRecurrenceCount    = 1000
+	minUsernameLength     = 3
+	maxUsernameLength     = 32
+	// bcrypt ignores everything after the first 72 bytes.
+	maxPasswordBytes = 72
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validateTask checks the editable fields of a task. The due date may only
+// be missing or lie in the past when it is unchanged from previous, so old
+// tasks can still be edited.
+func validateTask(task domain.Task, previous *domain.Task) domain.FieldErrors {
+	var errs domain.FieldErrors
+
+	title := strings.TrimSpace(task.Title)
+	switch {
+	case title == "":
+		errs.Add("title", "is required")
+	case utf8.RuneCountInString(title) > maxTitleLength:
+		errs.Add("title", fmt.Sprintf("must be at most %d characters", maxTitleLength))
+	}
+
+	dueDateChanged := previous == nil || !task.DueDate.Equal(previous.DueDate)
+	switch {
+	case !dueDateChanged:
+	case task.DueDate.IsZero():
+		errs.Add("due_date", "is required")
+	case task.DueDate.Before(time.Now()):
+		errs.Add("due_date", "must not be in the past")
+	}
+
+	if !domain.IsTaskStatus(task.Status) {
+		errs.Add("status", "must be one of "+strings.Join(domain.TaskStatuses, ", "))
+	}
+
+	// Tasks created before priorities existed have none until it is set.
+	priorityChanged := previous == nil || task.Priority != previous.Priority
+	if priorityChanged && !domain.IsTaskPriority(task.Priority) {
+		errs.Add("priority", "must be one of "+strings.Join(domain.TaskPriorities, ", "))
+	}
+
+	if task.EstimateMinutes < 0 || task.EstimateMinutes > maxEstimateMinutes {
+		errs.Add("estimate_minutes", fmt.Sprintf("must be between 0 and %d", maxEstimateMinutes))
+	}
+
+	return errs
+}
+
+// validateRecurrence checks a normalized rule for a series starting at
+// start. UNTIL and COUNT are mutually exclusive, as in RFC 5545.
+func validateRecurrence(rule domain.RecurrenceRule, start time.Time) domain.FieldErrors {
+	var errs domain.FieldErrors
+
+	if !domain.IsRecurrenceFrequency(rule.Freq) {
+		errs.Add("recurrence.freq", "must be one of "+strings.Join(domain.RecurrenceFrequencies, ", "))
+	}
+	if rule.Interval < 1 || rule.Interval > maxRecurrenceInterval {
+		errs.Add("recurrence.interval", fmt.Sprintf("must be between 1 and %d", maxRecurrenceInterval))
+	}
+	for _, day := range rule.ByWeekday {
+		if _, ok := domain.Weekdays[day]; !ok {
+			errs.Add("recurrence.by_weekday", fmt.Sprintf("%q is not one of MO, TU, WE, TH, FR, SA, SU", day))
+		}
+	}
+	if rule.TimeZone != "" {
+		if _, err := time.LoadLocation(rule.TimeZone); err != nil {
+			errs.Add("recurrence.tzid", "must be an IANA time zone such as Europe/Paris")
+		}
+	}
+	if rule.Count < 0 || rule.Count > maxRecurrenceCount {
+		errs.Add("recurrence.count", fmt.Sprintf("must be between 1 and %d", maxRecurrenceCount))
+	}
+	switch {
+	case rule.Until != nil && rule.Count > 0:
+		errs.Add("recurrence.until", "cannot be combined with count")
+	case rule.Until != nil && rule.Until.Before(start):
+		errs.Add("recurrence.until", "must not be before the due date")
+	}
+
+	return errs
+}
+
+func validateComment(body string) domain.FieldErrors {
+	var errs domain.FieldErrors
+
+	switch {
+	case body == "":
+		errs.Add("body", "is required")
+	case utf8.RuneCountInString(body) > maxCommentLength:
+		errs.Add("body", fmt.Sprintf("must be at most %d characters", maxCommentLength))
+	}
+
+	return errs
+}
+
+func validateTimeLogNote(note string) domain.FieldErrors {
+	var errs domain.FieldErrors
+
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		errs.Add("note", fmt.Sprintf("must be at most %d characters", maxNoteLength))
+	}
+
+	return errs
+}
+
+func validateCredentials(username, password string, policy domain.PasswordPolicy) domain.FieldErrors {
+	var errs domain.FieldErrors
+
+	switch n := utf8.RuneCountInString(username); {
+	case n < minUsernameLength || n > maxUsernameLength:
+		errs.Add("username", fmt.Sprintf("must be %d to %d characters", minUsernameLength, maxUsernameLength))
+	case !usernamePattern.MatchString(username):
+		errs.Add("username", "may only contain letters, digits, '.', '_' and '-', and must start with a letter or digit")
+	}
+
+	for _, message := range checkPassword(password, policy) {
+		errs.Add("password", message)
+	}
+
+	return errs
+}
+
+func checkPassword(password string, policy domain.PasswordPolicy) []string {
+	var problems []string
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters", policy.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		problems = append(problems, fmt.Sprintf("must be at most %d bytes", maxPasswordBytes))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if policy.RequireUpper && !upper {
+		problems = append(problems, "must contain an uppercase letter")
+	}
+	if policy.RequireLower && !lower {
+		problems = append(problems, "must contain a lowercase letter")
+	}
+	if policy.RequireDigit && !digit {
+		problems = append(problems, "must contain a digit")
+	}
+	if policy.RequireSymbol && !symbol {
+		problems = append(problems, "must contain a symbol")
+	}
+
+	if policy.Denylist[strings.ToLower(password)] {
+		problems = append(problems, "appears in a list of breached passwords")
+	}
+	return problems
+}
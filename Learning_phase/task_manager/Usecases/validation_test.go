@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"reflect"
+	"task_manager/Domain"
+	"testing"
+	"time"
+)
+
+func TestValidateTaskDueDate(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour).UTC()
+	past := time.Now().Add(-24 * time.Hour).UTC()
+	task := func(due time.Time) domain.Task {
+		return domain.Task{Title: "task", Status: domain.StatusTodo, Priority: domain.PriorityMedium, DueDate: due}
+	}
+	legacy := task(time.Time{})
+	overdue := task(past)
+
+	tests := []struct {
+		name       string
+		task       domain.Task
+		previous   *domain.Task
+		wantFields []string
+	}{
+		{name: "create", task: task(future)},
+		{name: "create without due date", task: task(time.Time{}), wantFields: []string{"due_date"}},
+		{name: "create in the past", task: task(past), wantFields: []string{"due_date"}},
+		{name: "legacy task without due date", task: task(time.Time{}), previous: &legacy},
+		{name: "legacy task gets a due date", task: task(future), previous: &legacy},
+		{name: "overdue task kept", task: task(past), previous: &overdue},
+		{name: "due date cleared", task: task(time.Time{}), previous: &overdue, wantFields: []string{"due_date"}},
+		{name: "due date moved into the past", task: task(past.Add(-time.Hour)), previous: &overdue, wantFields: []string{"due_date"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, fe := range validateTask(tt.task, tt.previous) {
+				fields = append(fields, fe.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("validateTask() errors on %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
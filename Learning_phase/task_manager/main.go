@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"task_manager/middleware"
 	"task_manager/router"
 	"time"
 
@@ -11,6 +12,10 @@ import (
 )
 
 func main() {
+	if err := middleware.CheckSecret(); err != nil {
+		log.Fatal(err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
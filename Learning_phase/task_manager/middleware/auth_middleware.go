@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -9,7 +11,25 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecret = []byte("your-secret-key-change-in-production")
+var jwtSecret = loadSecret()
+
+var ErrWeakSecret = errors.New("JWT_SECRET must be set to at least 32 characters")
+
+func loadSecret() []byte {
+	if secret := os.Getenv("JWT_SECRET"); len(secret) >= 32 {
+		return []byte(secret)
+	}
+	return nil
+}
+
+// CheckSecret reports whether JWT_SECRET is usable. Without it no token can
+// be issued or accepted.
+func CheckSecret() error {
+	if jwtSecret == nil {
+		return ErrWeakSecret
+	}
+	return nil
+}
 
 type Claims struct {
 	UserID   string `json:"user_id"`
@@ -29,6 +49,9 @@ func GenerateToken(userID, username, role string) (string, error) {
 		},
 	}
 
+	if err := CheckSecret(); err != nil {
+		return "", err
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
@@ -51,8 +74,11 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		claims := &Claims{}
 		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			if err := CheckSecret(); err != nil {
+				return nil, err
+			}
 			return jwtSecret, nil
-		})
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})